@@ -0,0 +1,252 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var clusterenvironmentlog = logf.Log.WithName("clusterenvironment-resource")
+
+type clusterEnvironmentValidator struct {
+	client client.Client
+	// extractRESTConfig builds the REST config of a worker cluster from a
+	// Cluster Context Secret
+	extractRESTConfig func(*corev1.Secret) (*rest.Config, error)
+}
+
+var _ admission.CustomValidator = &clusterEnvironmentValidator{}
+
+// SetupWebhookWithManager registers the ClusterEnvironment webhook.  The
+// Cluster Context Secrets are parsed with extractRESTConfig.
+func (r *ClusterEnvironment) SetupWebhookWithManager(mgr ctrl.Manager, extractRESTConfig func(*corev1.Secret) (*rest.Config, error)) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&clusterEnvironmentValidator{
+			client:            mgr.GetClient(),
+			extractRESTConfig: extractRESTConfig,
+		}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-primaza-io-v1alpha1-clusterenvironment,mutating=false,failurePolicy=fail,sideEffects=None,groups=primaza.io,resources=clusterenvironments,verbs=create;update,versions=v1alpha1,name=vclusterenvironment.kb.io,admissionReviewVersions=v1
+
+// ValidateNamespaces checks that namespaces are listed at most once, either
+// as application or as service namespace
+func (r *ClusterEnvironmentSpec) ValidateNamespaces() field.ErrorList {
+	errs := field.ErrorList{}
+	namespaces := map[string]struct{}{}
+	appPath := field.NewPath("spec", "applicationNamespaces")
+	for i, ns := range r.ApplicationNamespaces {
+		if _, found := namespaces[ns]; found {
+			errs = append(errs, field.Duplicate(appPath.Index(i), ns))
+		} else {
+			namespaces[ns] = struct{}{}
+		}
+	}
+
+	svcPath := field.NewPath("spec", "serviceNamespaces")
+	svcNamespaces := map[string]struct{}{}
+	for i, ns := range r.ServiceNamespaces {
+		_, isApp := namespaces[ns]
+		_, isSvc := svcNamespaces[ns]
+		switch {
+		case isSvc:
+			errs = append(errs, field.Duplicate(svcPath.Index(i), ns))
+		case isApp:
+			errs = append(errs, field.Invalid(svcPath.Index(i), ns, "namespace is also an application namespace"))
+		}
+		svcNamespaces[ns] = struct{}{}
+	}
+	return errs
+}
+
+// workerNamespaces returns the namespaces the ClusterEnvironment claims in the worker cluster
+func (r *ClusterEnvironmentSpec) workerNamespaces() map[string]struct{} {
+	namespaces := map[string]struct{}{}
+	for _, ns := range r.ApplicationNamespaces {
+		namespaces[ns] = struct{}{}
+	}
+	for _, ns := range r.ServiceNamespaces {
+		namespaces[ns] = struct{}{}
+	}
+	return namespaces
+}
+
+// clusterHost returns the API server the ClusterEnvironment targets, as read
+// from the Cluster Context Secret.  An empty host is returned, together with a
+// warning, when the secret does not exist yet.
+func (v *clusterEnvironmentValidator) clusterHost(ctx context.Context, ce ClusterEnvironment) (string, admission.Warnings, field.ErrorList, error) {
+	path := field.NewPath("spec", "clusterContextSecret")
+	if ce.Spec.ClusterContextSecret == "" {
+		return "", nil, field.ErrorList{field.Required(path, "cluster context secret is required")}, nil
+	}
+
+	var s corev1.Secret
+	k := types.NamespacedName{Namespace: ce.Namespace, Name: ce.Spec.ClusterContextSecret}
+	if err := v.client.Get(ctx, k, &s); err != nil {
+		if apierrors.IsNotFound(err) {
+			w := admission.Warnings{fmt.Sprintf("Cluster Context Secret %v not found", ce.Spec.ClusterContextSecret)}
+			return "", w, nil, nil
+		}
+		return "", nil, nil, err
+	}
+
+	cfg, err := v.extractRESTConfig(&s)
+	if err != nil {
+		msg := fmt.Sprintf("Cluster Context Secret does not contain a valid kubeconfig: %v", err)
+		return "", nil, field.ErrorList{field.Invalid(path, ce.Spec.ClusterContextSecret, msg)}, nil
+	}
+	return normalizeHost(cfg.Host), nil, nil, nil
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), "/")
+}
+
+// NamespaceOverlaps checks that no other ClusterEnvironment targeting the same
+// API server claims one of the ClusterEnvironment's namespaces
+func (v *clusterEnvironmentValidator) NamespaceOverlaps(ctx context.Context, ce ClusterEnvironment, host string) (field.ErrorList, error) {
+	cel := ClusterEnvironmentList{}
+	if err := v.client.List(ctx, &cel, client.InNamespace(ce.Namespace)); err != nil {
+		return nil, err
+	}
+
+	errs := field.ErrorList{}
+	namespaces := append(append([]string{}, ce.Spec.ApplicationNamespaces...), ce.Spec.ServiceNamespaces...)
+	for _, item := range cel.Items {
+		if item.Name == ce.Name {
+			continue
+		}
+
+		var s corev1.Secret
+		k := types.NamespacedName{Namespace: item.Namespace, Name: item.Spec.ClusterContextSecret}
+		if err := v.client.Get(ctx, k, &s); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		cfg, err := v.extractRESTConfig(&s)
+		if err != nil || normalizeHost(cfg.Host) != host {
+			continue
+		}
+
+		claimed := item.Spec.workerNamespaces()
+		for _, ns := range namespaces {
+			if _, found := claimed[ns]; found {
+				errs = append(errs, field.Forbidden(field.NewPath("spec"),
+					fmt.Sprintf("Cluster Environment %v already claims namespace %v in cluster %v", item.Name, ns, host)))
+			}
+		}
+	}
+	return errs, nil
+}
+
+func (v *clusterEnvironmentValidator) validate(ctx context.Context, ce ClusterEnvironment) (admission.Warnings, error) {
+	errs := ce.Spec.ValidateNamespaces()
+
+	host, warnings, herrs, err := v.clusterHost(ctx, ce)
+	if err != nil {
+		return nil, err
+	}
+	errs = append(errs, herrs...)
+	if host != "" {
+		oerrs, err := v.NamespaceOverlaps(ctx, ce, host)
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, oerrs...)
+	}
+
+	if len(errs) != 0 {
+		return nil, errs.ToAggregate()
+	}
+	return warnings, nil
+}
+
+// ValidateCreate implements admission.CustomValidator
+func (v *clusterEnvironmentValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	r, ok := obj.(*ClusterEnvironment)
+	if !ok {
+		err := fmt.Errorf("Object is not a Cluster Environment")
+		clusterenvironmentlog.Error(err, "Attempted to validate non-ClusterEnvironment resource", "gvk", obj.GetObjectKind().GroupVersionKind())
+		return nil, err
+	}
+
+	clusterenvironmentlog.Info("validate create", "name", r.Name)
+	return v.validate(ctx, *r)
+}
+
+// ValidateDelete implements admission.CustomValidator
+func (v *clusterEnvironmentValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	r, ok := obj.(*ClusterEnvironment)
+	if !ok {
+		err := fmt.Errorf("Object is not a Cluster Environment")
+		clusterenvironmentlog.Error(err, "Attempted to validate non-ClusterEnvironment resource", "gvk", obj.GetObjectKind().GroupVersionKind())
+		return nil, err
+	}
+
+	clusterenvironmentlog.Info("validate delete", "name", r.Name)
+	return nil, nil // no validation
+}
+
+// ValidateUpdate implements admission.CustomValidator
+func (v *clusterEnvironmentValidator) ValidateUpdate(ctx context.Context, oldObj runtime.Object, newObj runtime.Object) (admission.Warnings, error) {
+	r, ok := newObj.(*ClusterEnvironment)
+	if !ok {
+		err := fmt.Errorf("Object is not a Cluster Environment")
+		clusterenvironmentlog.Error(err, "Attempted to validate non-ClusterEnvironment resource", "gvk", newObj.GetObjectKind().GroupVersionKind())
+		return nil, err
+	}
+
+	clusterenvironmentlog.Info("validate update", "name", r.Name)
+
+	o, ok := oldObj.(*ClusterEnvironment)
+	if !ok {
+		return nil, fmt.Errorf("Old object is not a ClusterEnvironment")
+	}
+
+	// finalizers must be removable from ClusterEnvironments being deleted,
+	// even if their Cluster Context Secret is gone
+	if r.HasDeletionTimestamp() {
+		return nil, nil
+	}
+
+	// metadata updates must be allowed even for ClusterEnvironments created
+	// before the validation rules were in place
+	if reflect.DeepEqual(o.Spec, r.Spec) {
+		return nil, nil
+	}
+
+	return v.validate(ctx, *r)
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func newClusterEnvironment(name, namespace string, spec ClusterEnvironmentSpec) ClusterEnvironment {
+	return ClusterEnvironment{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: spec,
+	}
+}
+
+func newClusterContextSecret(name, host string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      name,
+			Namespace: "primaza-system",
+		},
+		Data: map[string][]byte{"kubeconfig": []byte(host)},
+	}
+}
+
+// fakeRESTConfig uses the kubeconfig field of the secret as API server host
+func fakeRESTConfig(s *corev1.Secret) (*rest.Config, error) {
+	h := string(s.Data["kubeconfig"])
+	if h == "" {
+		return nil, fmt.Errorf("invalid kubeconfig")
+	}
+	return &rest.Config{Host: h}, nil
+}
+
+var _ = Describe("ClusterEnvironment Webhook tests", func() {
+	var validator clusterEnvironmentValidator
+	tr := func(_ []string, err error) error { return err }
+
+	BeforeEach(func() {
+		schemeBuilder, err := SchemeBuilder.Build()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(corev1.AddToScheme(schemeBuilder)).To(Succeed())
+
+		other := newClusterEnvironment("other", "primaza-system", ClusterEnvironmentSpec{
+			EnvironmentName:       "dev",
+			ClusterContextSecret:  "other-kubeconfig",
+			ApplicationNamespaces: []string{"apps"},
+			ServiceNamespaces:     []string{"services"},
+		})
+		objs := []runtime.Object{
+			&other,
+			newClusterContextSecret("other-kubeconfig", "https://cluster-a:6443/"),
+			newClusterContextSecret("cluster-a", "https://cluster-a:6443"),
+			newClusterContextSecret("cluster-b", "https://cluster-b:6443"),
+			newClusterContextSecret("malformed", ""),
+		}
+		validator = clusterEnvironmentValidator{
+			client: fake.NewClientBuilder().
+				WithScheme(schemeBuilder).
+				WithRuntimeObjects(objs...).
+				Build(),
+			extractRESTConfig: fakeRESTConfig,
+		}
+	})
+
+	It("should accept valid cluster environments", func() {
+		ce := newClusterEnvironment("ce", "primaza-system", ClusterEnvironmentSpec{
+			EnvironmentName:       "dev",
+			ClusterContextSecret:  "cluster-a",
+			ApplicationNamespaces: []string{"apps-2"},
+			ServiceNamespaces:     []string{"services-2"},
+		})
+		w, err := validator.ValidateCreate(context.Background(), &ce)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w).To(BeEmpty())
+	})
+
+	It("should allow namespaces claimed in other clusters", func() {
+		ce := newClusterEnvironment("ce", "primaza-system", ClusterEnvironmentSpec{
+			EnvironmentName:       "dev",
+			ClusterContextSecret:  "cluster-b",
+			ApplicationNamespaces: []string{"apps"},
+			ServiceNamespaces:     []string{"services"},
+		})
+		Expect(tr(validator.ValidateCreate(context.Background(), &ce))).NotTo(HaveOccurred())
+	})
+
+	It("should warn when the cluster context secret does not exist", func() {
+		ce := newClusterEnvironment("ce", "primaza-system", ClusterEnvironmentSpec{
+			EnvironmentName:       "dev",
+			ClusterContextSecret:  "missing",
+			ApplicationNamespaces: []string{"apps"},
+		})
+		w, err := validator.ValidateCreate(context.Background(), &ce)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w).To(Equal(admission.Warnings{"Cluster Context Secret missing not found"}))
+	})
+
+	DescribeTable("Creation validation failures",
+		func(ce ClusterEnvironment, expected field.ErrorList) {
+			_, err := validator.ValidateCreate(context.Background(), &ce)
+			Expect(err).To(Equal(expected.ToAggregate()))
+		},
+		Entry("Missing cluster context secret",
+			newClusterEnvironment("ce", "primaza-system", ClusterEnvironmentSpec{
+				EnvironmentName:      "dev",
+				ClusterContextSecret: "",
+			}),
+			field.ErrorList{
+				field.Required(field.NewPath("spec", "clusterContextSecret"), "cluster context secret is required"),
+			}),
+		Entry("Malformed kubeconfig",
+			newClusterEnvironment("ce", "primaza-system", ClusterEnvironmentSpec{
+				EnvironmentName:      "dev",
+				ClusterContextSecret: "malformed",
+			}),
+			field.ErrorList{
+				field.Invalid(field.NewPath("spec", "clusterContextSecret"), "malformed",
+					"Cluster Context Secret does not contain a valid kubeconfig: invalid kubeconfig"),
+			}),
+		Entry("Namespace both application and service namespace",
+			newClusterEnvironment("ce", "primaza-system", ClusterEnvironmentSpec{
+				EnvironmentName:       "dev",
+				ClusterContextSecret:  "cluster-b",
+				ApplicationNamespaces: []string{"ns", "ns"},
+				ServiceNamespaces:     []string{"ns"},
+			}),
+			field.ErrorList{
+				field.Duplicate(field.NewPath("spec", "applicationNamespaces").Index(1), "ns"),
+				field.Invalid(field.NewPath("spec", "serviceNamespaces").Index(0), "ns", "namespace is also an application namespace"),
+			}),
+		Entry("Namespaces claimed by another cluster environment",
+			newClusterEnvironment("ce", "primaza-system", ClusterEnvironmentSpec{
+				EnvironmentName:       "dev",
+				ClusterContextSecret:  "cluster-a",
+				ApplicationNamespaces: []string{"services"},
+				ServiceNamespaces:     []string{"apps"},
+			}),
+			field.ErrorList{
+				field.Forbidden(field.NewPath("spec"), "Cluster Environment other already claims namespace services in cluster https://cluster-a:6443"),
+				field.Forbidden(field.NewPath("spec"), "Cluster Environment other already claims namespace apps in cluster https://cluster-a:6443"),
+			}),
+	)
+
+	It("should allow updates of the same cluster environment", func() {
+		oldCE := newClusterEnvironment("other", "primaza-system", ClusterEnvironmentSpec{
+			EnvironmentName:       "dev",
+			ClusterContextSecret:  "other-kubeconfig",
+			ApplicationNamespaces: []string{"apps"},
+			ServiceNamespaces:     []string{"services"},
+		})
+		ce := newClusterEnvironment("other", "primaza-system", ClusterEnvironmentSpec{
+			EnvironmentName:       "dev",
+			ClusterContextSecret:  "other-kubeconfig",
+			ApplicationNamespaces: []string{"apps"},
+			ServiceNamespaces:     []string{"services", "more"},
+		})
+		Expect(tr(validator.ValidateUpdate(context.Background(), &oldCE, &ce))).NotTo(HaveOccurred())
+	})
+
+	It("should allow metadata updates of invalid cluster environments", func() {
+		oldCE := newClusterEnvironment("ce", "primaza-system", ClusterEnvironmentSpec{
+			EnvironmentName:      "dev",
+			ClusterContextSecret: "malformed",
+		})
+		ce := newClusterEnvironment("ce", "primaza-system", ClusterEnvironmentSpec{
+			EnvironmentName:      "dev",
+			ClusterContextSecret: "malformed",
+		})
+		ce.Finalizers = []string{"primaza.io/finalizer"}
+		Expect(tr(validator.ValidateUpdate(context.Background(), &oldCE, &ce))).NotTo(HaveOccurred())
+	})
+
+	It("should validate spec updates of invalid cluster environments", func() {
+		oldCE := newClusterEnvironment("ce", "primaza-system", ClusterEnvironmentSpec{
+			EnvironmentName:      "dev",
+			ClusterContextSecret: "malformed",
+		})
+		ce := newClusterEnvironment("ce", "primaza-system", ClusterEnvironmentSpec{
+			EnvironmentName:       "dev",
+			ClusterContextSecret:  "malformed",
+			ApplicationNamespaces: []string{"apps-2"},
+		})
+		Expect(tr(validator.ValidateUpdate(context.Background(), &oldCE, &ce))).To(HaveOccurred())
+	})
+
+	It("should allow updates of cluster environments being deleted", func() {
+		ce := newClusterEnvironment("ce", "primaza-system", ClusterEnvironmentSpec{
+			EnvironmentName:      "dev",
+			ClusterContextSecret: "",
+		})
+		now := v1.Now()
+		ce.DeletionTimestamp = &now
+		Expect(tr(validator.ValidateUpdate(context.Background(), &ce, &ce))).NotTo(HaveOccurred())
+	})
+
+	It("should reject non-ClusterEnvironment objects", func() {
+		oldObject := unstructured.Unstructured{}
+		newObject := newClusterEnvironment("ce", "primaza-system", ClusterEnvironmentSpec{
+			EnvironmentName:      "dev",
+			ClusterContextSecret: "cluster-a",
+		})
+
+		Expect(tr(validator.ValidateCreate(context.Background(), &oldObject))).To(HaveOccurred())
+		Expect(tr(validator.ValidateUpdate(context.Background(), &oldObject, &newObject))).To(HaveOccurred())
+		Expect(tr(validator.ValidateUpdate(context.Background(), &newObject, &oldObject))).To(HaveOccurred())
+		Expect(tr(validator.ValidateDelete(context.Background(), &oldObject))).To(HaveOccurred())
+	})
+})
@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"slices"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -36,6 +38,30 @@ type RegisteredServiceConstraints struct {
 	Environments []string `json:"environments,omitempty"`
 }
 
+type RegisteredServiceSharingMode string
+
+const (
+	RegisteredServiceSharingModeExclusive RegisteredServiceSharingMode = "Exclusive"
+	RegisteredServiceSharingModeShared    RegisteredServiceSharingMode = "Shared"
+)
+
+// RegisteredServiceSharing defines how many ServiceClaims may be bound to the
+// RegisteredService at the same time.
+type RegisteredServiceSharing struct {
+	// Mode defines whether the RegisteredService can be bound to just one
+	// ServiceClaim (Exclusive) or to many of them (Shared).
+	//+kubebuilder:validation:Enum=Exclusive;Shared
+	//+kubebuilder:default:=Exclusive
+	Mode RegisteredServiceSharingMode `json:"mode"`
+
+	// MaxClaims defines the maximum number of ServiceClaims that can be bound
+	// to a Shared RegisteredService. If not set, the number of ServiceClaims
+	// is not limited. It is ignored for Exclusive RegisteredServices.
+	// +optional
+	//+kubebuilder:validation:Minimum=1
+	MaxClaims int `json:"maxClaims,omitempty"`
+}
+
 // ServiceEndpointDefinitionSecretRef defines a reference to
 // one of the keys of a secret. This reference can then be used
 // when defining a ServiceEndpointDefinitionItem
@@ -47,6 +73,17 @@ type ServiceEndpointDefinitionSecretRef struct {
 	Key string `json:"key"`
 }
 
+// ServiceEndpointDefinitionProviderRef defines a reference to a value stored
+// in an external store, resolved by the named provider.  This reference can
+// then be used when defining a ServiceEndpointDefinitionItem
+type ServiceEndpointDefinitionProviderRef struct {
+	// Name of the provider resolving the reference
+	Name string `json:"name"`
+
+	// Key of the value in the provider's store
+	Key string `json:"key"`
+}
+
 // ServiceEndpointDefinitionItem defines an attribute that is necessary for
 // a client to connect to a service
 type ServiceEndpointDefinitionItem struct {
@@ -54,14 +91,19 @@ type ServiceEndpointDefinitionItem struct {
 	Name string `json:"name"`
 
 	// Value of the service endpoint definition attribute. It is mutually
-	// exclusive with ValueFromSecret.
+	// exclusive with ValueFromSecret and ValueFromProvider.
 	// +optional
 	Value string `json:"value,omitempty"`
 
 	// Value reference of the service endpoint definition attribute. It is mutually
-	// exclusive with Value
+	// exclusive with Value and ValueFromProvider
 	// +optional
 	ValueFromSecret *ServiceEndpointDefinitionSecretRef `json:"valueFromSecret,omitempty"`
+
+	// Reference to a value of the service endpoint definition attribute stored
+	// in an external store. It is mutually exclusive with Value and ValueFromSecret
+	// +optional
+	ValueFromProvider *ServiceEndpointDefinitionProviderRef `json:"valueFromProvider,omitempty"`
 }
 
 // RegisteredServiceSpec defines the desired state of RegisteredService
@@ -79,6 +121,11 @@ type RegisteredServiceSpec struct {
 	// +optional
 	SLA string `json:"sla,omitempty"`
 
+	// Sharing defines how many ServiceClaims may be bound to the service.
+	// If not set, the service is bound exclusively to one ServiceClaim.
+	// +optional
+	Sharing *RegisteredServiceSharing `json:"sharing,omitempty"`
+
 	// ServiceClassIdentity defines a set of attributes that are sufficient to
 	// identify a service class.  A ServiceClaim whose ServiceClassIdentity
 	// field is a subset of a RegisteredService's keys can claim that service.
@@ -104,11 +151,16 @@ type RegisteredServiceStatus struct {
 	//+kubebuilder:validation:Enum=Available;Claimed;Unknown;Unreachable
 	//+kubebuilder:default:=Unknown
 	State RegisteredServiceState `json:"state,omitempty"`
+
+	// Claims contains the IDs of the ServiceClaims bound to the service.
+	// +optional
+	Claims []string `json:"claims,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="State",type="string",JSONPath=".status.state",description="the state of the RegisteredService"
+//+kubebuilder:printcolumn:name="Sharing",type="string",JSONPath=".spec.sharing.mode",description="the sharing mode of the RegisteredService"
 //+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // RegisteredService is the Schema for the registeredservices API.
@@ -132,3 +184,74 @@ type RegisteredServiceList struct {
 func init() {
 	SchemeBuilder.Register(&RegisteredService{}, &RegisteredServiceList{})
 }
+
+// IsShared returns true if the RegisteredService can be bound to more than one ServiceClaim
+func (rs *RegisteredService) IsShared() bool {
+	return rs.Spec.Sharing != nil && rs.Spec.Sharing.Mode == RegisteredServiceSharingModeShared
+}
+
+// HasCapacity returns true if one more ServiceClaim can be bound to the RegisteredService
+func (rs *RegisteredService) HasCapacity() bool {
+	if !rs.IsShared() {
+		return len(rs.Status.Claims) == 0
+	}
+	return rs.Spec.Sharing.MaxClaims == 0 || len(rs.Status.Claims) < rs.Spec.Sharing.MaxClaims
+}
+
+// IsClaimedBy returns true if the ServiceClaim with the given ID is bound to the RegisteredService
+func (rs *RegisteredService) IsClaimedBy(claimID string) bool {
+	return slices.Contains(rs.Status.Claims, claimID)
+}
+
+// AddClaim binds the ServiceClaim with the given ID to the RegisteredService
+// and updates the state accordingly. It returns false if the claim was already
+// bound or if there is no capacity left.
+func (rs *RegisteredService) AddClaim(claimID string) bool {
+	if rs.IsClaimedBy(claimID) || !rs.HasCapacity() {
+		return false
+	}
+
+	rs.Status.Claims = append(rs.Status.Claims, claimID)
+	rs.refreshClaimsState()
+	return true
+}
+
+// RemoveClaim unbinds the ServiceClaim with the given ID from the RegisteredService
+// and updates the state accordingly. It returns false if the claim was not bound.
+func (rs *RegisteredService) RemoveClaim(claimID string) bool {
+	i := slices.Index(rs.Status.Claims, claimID)
+	if i < 0 {
+		return false
+	}
+
+	rs.Status.Claims = slices.Delete(rs.Status.Claims, i, i+1)
+	rs.refreshClaimsState()
+	return true
+}
+
+// ClaimsState returns the state a healthy RegisteredService should be in
+// given the ServiceClaims bound to it: Claimed if no more capacity is left,
+// Available otherwise.
+func (rs *RegisteredService) ClaimsState() RegisteredServiceState {
+	// services claimed before claims were tracked are considered at capacity
+	if rs.Status.State == RegisteredServiceStateClaimed && len(rs.Status.Claims) == 0 {
+		return RegisteredServiceStateClaimed
+	}
+
+	return rs.capacityState()
+}
+
+// refreshClaimsState updates the state of a healthy RegisteredService
+// after the list of bound ServiceClaims changed
+func (rs *RegisteredService) refreshClaimsState() {
+	if rs.Status.State == RegisteredServiceStateAvailable || rs.Status.State == RegisteredServiceStateClaimed {
+		rs.Status.State = rs.capacityState()
+	}
+}
+
+func (rs *RegisteredService) capacityState() RegisteredServiceState {
+	if rs.HasCapacity() {
+		return RegisteredServiceStateAvailable
+	}
+	return RegisteredServiceStateClaimed
+}
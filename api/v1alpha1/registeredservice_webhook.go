@@ -0,0 +1,245 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/primaza/primaza/pkg/envtag"
+)
+
+// log is for logging in this package.
+var registeredservicelog = logf.Log.WithName("registeredservice-resource")
+
+type registeredServiceValidator struct {
+	client client.Client
+}
+
+var _ admission.CustomValidator = &registeredServiceValidator{}
+
+func (r *RegisteredService) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&registeredServiceValidator{
+			client: mgr.GetClient(),
+		}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-primaza-io-v1alpha1-registeredservice,mutating=false,failurePolicy=fail,sideEffects=None,groups=primaza.io,resources=registeredservices,verbs=create;update,versions=v1alpha1,name=vregisteredservice.kb.io,admissionReviewVersions=v1
+
+// ValidateServiceEndpointDefinition checks that names are unique and that
+// each item defines at most one of Value, ValueFromSecret, and ValueFromProvider
+func (r *RegisteredServiceSpec) ValidateServiceEndpointDefinition() field.ErrorList {
+	errs := field.ErrorList{}
+	names := map[string]struct{}{}
+	childPath := field.NewPath("spec", "serviceEndpointDefinition")
+	for i, sed := range r.ServiceEndpointDefinition {
+		path := childPath.Index(i)
+		if _, found := names[sed.Name]; found {
+			errs = append(errs, field.Duplicate(path.Child("name"), sed.Name))
+		} else {
+			names[sed.Name] = struct{}{}
+		}
+
+		if sed.ValueFromSecret != nil {
+			if sed.Value != "" {
+				errs = append(errs, field.Invalid(path.Child("valueFromSecret"), sed.ValueFromSecret, "value and valueFromSecret are mutually exclusive"))
+			}
+			if sed.ValueFromSecret.Name == "" {
+				errs = append(errs, field.Required(path.Child("valueFromSecret", "name"), "secret name is required"))
+			}
+			if sed.ValueFromSecret.Key == "" {
+				errs = append(errs, field.Required(path.Child("valueFromSecret", "key"), "secret key is required"))
+			}
+		}
+
+		if sed.ValueFromProvider != nil {
+			if sed.Value != "" || sed.ValueFromSecret != nil {
+				errs = append(errs, field.Invalid(path.Child("valueFromProvider"), sed.ValueFromProvider, "valueFromProvider is mutually exclusive with value and valueFromSecret"))
+			}
+			if sed.ValueFromProvider.Name == "" {
+				errs = append(errs, field.Required(path.Child("valueFromProvider", "name"), "provider name is required"))
+			}
+			if sed.ValueFromProvider.Key == "" {
+				errs = append(errs, field.Required(path.Child("valueFromProvider", "key"), "provider key is required"))
+			}
+		}
+	}
+
+	return errs
+}
+
+// ValidateServiceClassIdentity checks that identity keys are unique
+func (r *RegisteredServiceSpec) ValidateServiceClassIdentity() field.ErrorList {
+	errs := field.ErrorList{}
+	names := map[string]struct{}{}
+	childPath := field.NewPath("spec", "serviceClassIdentity")
+	for i, sci := range r.ServiceClassIdentity {
+		if _, found := names[sci.Name]; found {
+			errs = append(errs, field.Duplicate(childPath.Index(i).Child("name"), sci.Name))
+		} else {
+			names[sci.Name] = struct{}{}
+		}
+	}
+	return errs
+}
+
+// ValidateHealthCheck checks that the health check container can be run
+func (r *RegisteredServiceSpec) ValidateHealthCheck() field.ErrorList {
+	errs := field.ErrorList{}
+	if r.HealthCheck == nil {
+		return errs
+	}
+
+	childPath := field.NewPath("spec", "healthcheck", "container")
+	c := r.HealthCheck.Container
+	if c.Image == "" {
+		errs = append(errs, field.Required(childPath.Child("image"), "container image is required"))
+	}
+	if len(c.Command) == 0 {
+		errs = append(errs, field.Required(childPath.Child("command"), "container command is required"))
+	}
+	if c.Minutes < 1 {
+		errs = append(errs, field.Invalid(childPath.Child("minutes"), c.Minutes, "minutes must be greater than or equal to 1"))
+	}
+	return errs
+}
+
+// ValidateConstraints checks the syntax of the environment constraints
+func (r *RegisteredServiceSpec) ValidateConstraints() field.ErrorList {
+	errs := field.ErrorList{}
+	childPath := field.NewPath("spec", "constraints", "environments")
+	for i, c := range r.GetEnvironmentConstraints() {
+		if err := envtag.ValidateConstraint(c); err != nil {
+			errs = append(errs, field.Invalid(childPath.Index(i), c, err.Error()))
+		}
+	}
+	return errs
+}
+
+func (r *RegisteredServiceSpec) validate() field.ErrorList {
+	errs := r.ValidateServiceEndpointDefinition()
+	errs = append(errs, r.ValidateServiceClassIdentity()...)
+	errs = append(errs, r.ValidateHealthCheck()...)
+	errs = append(errs, r.ValidateConstraints()...)
+	return errs
+}
+
+// ValidateCreate implements admission.CustomValidator
+func (v *registeredServiceValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	r, ok := obj.(*RegisteredService)
+	if !ok {
+		err := fmt.Errorf("Object is not a Registered Service")
+		registeredservicelog.Error(err, "Attempted to validate non-RegisteredService resource", "gvk", obj.GetObjectKind().GroupVersionKind())
+		return nil, err
+	}
+
+	registeredservicelog.Info("validate create", "name", r.Name)
+	if errs := r.Spec.validate(); len(errs) != 0 {
+		return nil, errs.ToAggregate()
+	}
+	return v.IdentityCollisions(ctx, *r)
+}
+
+// ValidateDelete implements admission.CustomValidator
+func (v *registeredServiceValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	r, ok := obj.(*RegisteredService)
+	if !ok {
+		err := fmt.Errorf("Object is not a Registered Service")
+		registeredservicelog.Error(err, "Attempted to validate non-RegisteredService resource", "gvk", obj.GetObjectKind().GroupVersionKind())
+		return nil, err
+	}
+
+	registeredservicelog.Info("validate delete", "name", r.Name)
+	return nil, nil // no validation
+}
+
+// ValidateUpdate implements admission.CustomValidator
+func (v *registeredServiceValidator) ValidateUpdate(ctx context.Context, oldObj runtime.Object, newObj runtime.Object) (admission.Warnings, error) {
+	r, ok := newObj.(*RegisteredService)
+	if !ok {
+		err := fmt.Errorf("Object is not a Registered Service")
+		registeredservicelog.Error(err, "Attempted to validate non-RegisteredService resource", "gvk", newObj.GetObjectKind().GroupVersionKind())
+		return nil, err
+	}
+
+	registeredservicelog.Info("validate update", "name", r.Name)
+
+	if _, ok := oldObj.(*RegisteredService); !ok {
+		return nil, fmt.Errorf("Old object is not a RegisteredService")
+	}
+
+	// finalizers must be removable from RegisteredServices being deleted
+	if !r.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+
+	if errs := r.Spec.validate(); len(errs) != 0 {
+		return nil, errs.ToAggregate()
+	}
+	return v.IdentityCollisions(ctx, *r)
+}
+
+// IdentityCollisions returns a warning for each RegisteredService in the same
+// namespace having the same ServiceClassIdentity. ServiceClaims can not tell
+// such RegisteredServices apart, and will be bound to the best ranked one.
+func (v *registeredServiceValidator) IdentityCollisions(ctx context.Context, registeredService RegisteredService) (admission.Warnings, error) {
+	rsl := RegisteredServiceList{}
+	if err := v.client.List(ctx, &rsl, client.InNamespace(registeredService.Namespace)); err != nil {
+		return nil, err
+	}
+
+	warnings := admission.Warnings{}
+	for _, item := range rsl.Items {
+		if item.Name != registeredService.Name &&
+			sameServiceClassIdentity(item.Spec.ServiceClassIdentity, registeredService.Spec.ServiceClassIdentity) {
+			warnings = append(warnings,
+				fmt.Sprintf("Registered Service %v has the same service class identity", item.Name))
+		}
+	}
+
+	if len(warnings) == 0 {
+		return nil, nil
+	}
+	return warnings, nil
+}
+
+func sameServiceClassIdentity(a, b []ServiceClassIdentityItem) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	items := map[ServiceClassIdentityItem]struct{}{}
+	for _, i := range a {
+		items[i] = struct{}{}
+	}
+	for _, i := range b {
+		if _, found := items[i]; !found {
+			return false
+		}
+	}
+	return true
+}
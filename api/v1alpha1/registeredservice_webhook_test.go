@@ -0,0 +1,277 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func newRegisteredService(name, namespace string, spec RegisteredServiceSpec) RegisteredService {
+	return RegisteredService{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: spec,
+	}
+}
+
+var _ = Describe("RegisteredService Webhook tests", func() {
+	var validator registeredServiceValidator
+	tr := func(_ []string, err error) error { return err }
+
+	BeforeEach(func() {
+		schemeBuilder, err := SchemeBuilder.Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		validator = registeredServiceValidator{
+			client: fake.NewClientBuilder().
+				WithScheme(schemeBuilder).
+				WithLists(&RegisteredServiceList{}).
+				Build(),
+		}
+	})
+
+	It("should accept valid registered services", func() {
+		rs := newRegisteredService("db", "primaza-system", RegisteredServiceSpec{
+			ServiceClassIdentity: []ServiceClassIdentityItem{
+				{Name: "type", Value: "psql"},
+				{Name: "provider", Value: "aws"},
+			},
+			ServiceEndpointDefinition: []ServiceEndpointDefinitionItem{
+				{Name: "host", Value: "db.example.com"},
+				{Name: "password", ValueFromSecret: &ServiceEndpointDefinitionSecretRef{Name: "db", Key: "password"}},
+			},
+			Constraints: &RegisteredServiceConstraints{Environments: []string{"dev", "!prod"}},
+			HealthCheck: &HealthCheck{
+				Container: HealthCheckContainer{Image: "postgres:15", Command: []string{"pg_isready"}, Minutes: 5},
+			},
+		})
+		w, err := validator.ValidateCreate(context.Background(), &rs)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w).To(BeEmpty())
+	})
+
+	DescribeTable("Creation validation failures",
+		func(rs RegisteredService, expected field.ErrorList) {
+			_, err := validator.ValidateCreate(context.Background(), &rs)
+			Expect(err).To(Equal(expected.ToAggregate()))
+		},
+		Entry("Value and ValueFromSecret",
+			newRegisteredService("db", "primaza-system", RegisteredServiceSpec{
+				ServiceClassIdentity: []ServiceClassIdentityItem{
+					{Name: "type", Value: "psql"},
+					{Name: "provider", Value: "aws"},
+				},
+				ServiceEndpointDefinition: []ServiceEndpointDefinitionItem{
+					{Name: "host", Value: "db.example.com"},
+					{Name: "password", Value: "secret", ValueFromSecret: &ServiceEndpointDefinitionSecretRef{Name: "db", Key: "password"}},
+				},
+			}),
+			field.ErrorList{
+				field.Invalid(field.NewPath("spec", "serviceEndpointDefinition").Index(1).Child("valueFromSecret"),
+					&ServiceEndpointDefinitionSecretRef{Name: "db", Key: "password"},
+					"value and valueFromSecret are mutually exclusive"),
+			}),
+		Entry("Incomplete ValueFromSecret",
+			newRegisteredService("db", "primaza-system", RegisteredServiceSpec{
+				ServiceClassIdentity: []ServiceClassIdentityItem{
+					{Name: "type", Value: "psql"},
+					{Name: "provider", Value: "aws"},
+				},
+				ServiceEndpointDefinition: []ServiceEndpointDefinitionItem{
+					{Name: "host", Value: "db.example.com"},
+					{Name: "password", ValueFromSecret: &ServiceEndpointDefinitionSecretRef{}},
+				},
+			}),
+			field.ErrorList{
+				field.Required(field.NewPath("spec", "serviceEndpointDefinition").Index(1).Child("valueFromSecret", "name"), "secret name is required"),
+				field.Required(field.NewPath("spec", "serviceEndpointDefinition").Index(1).Child("valueFromSecret", "key"), "secret key is required"),
+			}),
+		Entry("ValueFromSecret and ValueFromProvider",
+			newRegisteredService("db", "primaza-system", RegisteredServiceSpec{
+				ServiceClassIdentity: []ServiceClassIdentityItem{
+					{Name: "type", Value: "psql"},
+					{Name: "provider", Value: "aws"},
+				},
+				ServiceEndpointDefinition: []ServiceEndpointDefinitionItem{
+					{Name: "host", Value: "db.example.com"},
+					{
+						Name:              "password",
+						ValueFromSecret:   &ServiceEndpointDefinitionSecretRef{Name: "db", Key: "password"},
+						ValueFromProvider: &ServiceEndpointDefinitionProviderRef{Name: "vault", Key: "db/password"},
+					},
+				},
+			}),
+			field.ErrorList{
+				field.Invalid(field.NewPath("spec", "serviceEndpointDefinition").Index(1).Child("valueFromProvider"),
+					&ServiceEndpointDefinitionProviderRef{Name: "vault", Key: "db/password"},
+					"valueFromProvider is mutually exclusive with value and valueFromSecret"),
+			}),
+		Entry("Incomplete ValueFromProvider",
+			newRegisteredService("db", "primaza-system", RegisteredServiceSpec{
+				ServiceClassIdentity: []ServiceClassIdentityItem{
+					{Name: "type", Value: "psql"},
+					{Name: "provider", Value: "aws"},
+				},
+				ServiceEndpointDefinition: []ServiceEndpointDefinitionItem{
+					{Name: "host", Value: "db.example.com"},
+					{Name: "password", ValueFromProvider: &ServiceEndpointDefinitionProviderRef{}},
+				},
+			}),
+			field.ErrorList{
+				field.Required(field.NewPath("spec", "serviceEndpointDefinition").Index(1).Child("valueFromProvider", "name"), "provider name is required"),
+				field.Required(field.NewPath("spec", "serviceEndpointDefinition").Index(1).Child("valueFromProvider", "key"), "provider key is required"),
+			}),
+		Entry("Duplicate ServiceEndpointDefinition names",
+			newRegisteredService("db", "primaza-system", RegisteredServiceSpec{
+				ServiceClassIdentity: []ServiceClassIdentityItem{
+					{Name: "type", Value: "psql"},
+					{Name: "provider", Value: "aws"},
+				},
+				ServiceEndpointDefinition: []ServiceEndpointDefinitionItem{
+					{Name: "host", Value: "db.example.com"},
+					{Name: "host", ValueFromSecret: &ServiceEndpointDefinitionSecretRef{Name: "db", Key: "password"}},
+				},
+			}),
+			field.ErrorList{
+				field.Duplicate(field.NewPath("spec", "serviceEndpointDefinition").Index(1).Child("name"), "host"),
+			}),
+		Entry("Duplicate ServiceClassIdentity keys",
+			newRegisteredService("db", "primaza-system", RegisteredServiceSpec{
+				ServiceClassIdentity: []ServiceClassIdentityItem{
+					{Name: "type", Value: "psql"},
+					{Name: "type", Value: "aws"},
+				},
+				ServiceEndpointDefinition: []ServiceEndpointDefinitionItem{
+					{Name: "host", Value: "db.example.com"},
+					{Name: "password", ValueFromSecret: &ServiceEndpointDefinitionSecretRef{Name: "db", Key: "password"}},
+				},
+			}),
+			field.ErrorList{
+				field.Duplicate(field.NewPath("spec", "serviceClassIdentity").Index(1).Child("name"), "type"),
+			}),
+		Entry("Invalid HealthCheck",
+			newRegisteredService("db", "primaza-system", RegisteredServiceSpec{
+				ServiceClassIdentity: []ServiceClassIdentityItem{
+					{Name: "type", Value: "psql"},
+					{Name: "provider", Value: "aws"},
+				},
+				ServiceEndpointDefinition: []ServiceEndpointDefinitionItem{
+					{Name: "host", Value: "db.example.com"},
+					{Name: "password", ValueFromSecret: &ServiceEndpointDefinitionSecretRef{Name: "db", Key: "password"}},
+				},
+				HealthCheck: &HealthCheck{},
+			}),
+			field.ErrorList{
+				field.Required(field.NewPath("spec", "healthcheck", "container", "image"), "container image is required"),
+				field.Required(field.NewPath("spec", "healthcheck", "container", "command"), "container command is required"),
+				field.Invalid(field.NewPath("spec", "healthcheck", "container", "minutes"), uint32(0), "minutes must be greater than or equal to 1"),
+			}),
+		Entry("Invalid constraints",
+			newRegisteredService("db", "primaza-system", RegisteredServiceSpec{
+				ServiceClassIdentity: []ServiceClassIdentityItem{
+					{Name: "type", Value: "psql"},
+					{Name: "provider", Value: "aws"},
+				},
+				ServiceEndpointDefinition: []ServiceEndpointDefinitionItem{
+					{Name: "host", Value: "db.example.com"},
+					{Name: "password", ValueFromSecret: &ServiceEndpointDefinitionSecretRef{Name: "db", Key: "password"}},
+				},
+				Constraints: &RegisteredServiceConstraints{Environments: []string{"dev", "!!prod"}},
+			}),
+			field.ErrorList{
+				field.Invalid(field.NewPath("spec", "constraints", "environments").Index(1), "!!prod", `constraint "!!prod" is negated more than once`),
+			}),
+	)
+
+	It("should warn about registered services with the same identity", func() {
+		other := newRegisteredService("other", "primaza-system", RegisteredServiceSpec{
+			ServiceClassIdentity: []ServiceClassIdentityItem{
+				{Name: "type", Value: "psql"},
+				{Name: "provider", Value: "aws"},
+			},
+			ServiceEndpointDefinition: []ServiceEndpointDefinitionItem{
+				{Name: "host", Value: "db.example.com"},
+				{Name: "password", ValueFromSecret: &ServiceEndpointDefinitionSecretRef{Name: "db", Key: "password"}},
+			},
+		})
+		different := newRegisteredService("different", "primaza-system", RegisteredServiceSpec{
+			ServiceClassIdentity: []ServiceClassIdentityItem{
+				{Name: "type", Value: "psql"},
+				{Name: "provider", Value: "gcp"},
+			},
+			ServiceEndpointDefinition: []ServiceEndpointDefinitionItem{
+				{Name: "host", Value: "db.example.com"},
+				{Name: "password", ValueFromSecret: &ServiceEndpointDefinitionSecretRef{Name: "db", Key: "password"}},
+			},
+		})
+		schemeBuilder, err := SchemeBuilder.Build()
+		Expect(err).NotTo(HaveOccurred())
+		validator = registeredServiceValidator{
+			client: fake.NewClientBuilder().
+				WithScheme(schemeBuilder).
+				WithObjects(&other, &different).
+				Build(),
+		}
+
+		rs := newRegisteredService("db", "primaza-system", RegisteredServiceSpec{
+			ServiceClassIdentity: []ServiceClassIdentityItem{
+				{Name: "provider", Value: "aws"},
+				{Name: "type", Value: "psql"},
+			},
+			ServiceEndpointDefinition: []ServiceEndpointDefinitionItem{
+				{Name: "host", Value: "db.example.com"},
+				{Name: "password", ValueFromSecret: &ServiceEndpointDefinitionSecretRef{Name: "db", Key: "password"}},
+			},
+		})
+		w, err := validator.ValidateCreate(context.Background(), &rs)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w).To(Equal(admission.Warnings{"Registered Service other has the same service class identity"}))
+
+		w, err = validator.ValidateUpdate(context.Background(), &rs, &rs)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w).To(HaveLen(1))
+	})
+
+	It("should reject non-RegisteredService objects", func() {
+		oldObject := unstructured.Unstructured{}
+		newObject := newRegisteredService("db", "primaza-system", RegisteredServiceSpec{
+			ServiceClassIdentity: []ServiceClassIdentityItem{
+				{Name: "type", Value: "psql"},
+				{Name: "provider", Value: "aws"},
+			},
+			ServiceEndpointDefinition: []ServiceEndpointDefinitionItem{
+				{Name: "host", Value: "db.example.com"},
+				{Name: "password", ValueFromSecret: &ServiceEndpointDefinitionSecretRef{Name: "db", Key: "password"}},
+			},
+		})
+
+		Expect(tr(validator.ValidateCreate(context.Background(), &oldObject))).To(HaveOccurred())
+		Expect(tr(validator.ValidateUpdate(context.Background(), &oldObject, &newObject))).To(HaveOccurred())
+		Expect(tr(validator.ValidateUpdate(context.Background(), &newObject, &oldObject))).To(HaveOccurred())
+		Expect(tr(validator.ValidateDelete(context.Background(), &oldObject))).To(HaveOccurred())
+	})
+})
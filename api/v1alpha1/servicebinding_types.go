@@ -31,6 +31,15 @@ const (
 
 type ServiceBindingState string
 
+// ServiceBindingRestartPolicy defines whether bound workloads are restarted
+// when the ServiceEndpointDefinitionSecret changes
+type ServiceBindingRestartPolicy string
+
+const (
+	ServiceBindingRestartPolicyNever          ServiceBindingRestartPolicy = "Never"
+	ServiceBindingRestartPolicyOnSecretChange ServiceBindingRestartPolicy = "OnSecretChange"
+)
+
 // ServiceBindingSpec defines the desired state of ServiceBinding
 type ServiceBindingSpec struct {
 
@@ -53,6 +62,13 @@ type ServiceBindingSpec struct {
 	// projected into the application
 	// +optional
 	Envs []Environment `json:"envs,omitempty"`
+
+	// RestartPolicy defines whether bound workloads are restarted when the
+	// content of the ServiceEndpointDefinitionSecret changes
+	// +optional
+	//+kubebuilder:validation:Enum=Never;OnSecretChange
+	//+kubebuilder:default:=Never
+	RestartPolicy ServiceBindingRestartPolicy `json:"restartPolicy,omitempty"`
 }
 
 // ServiceBindingStatus defines the observed state of ServiceBinding.
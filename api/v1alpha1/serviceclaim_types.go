@@ -24,10 +24,21 @@ import (
 type ServiceClaimState string
 
 const (
-	ServiceClaimConditionReady ServiceClaimState = "Ready"
-	ServiceClaimStatePending   ServiceClaimState = "Pending"
-	ServiceClaimStateResolved  ServiceClaimState = "Resolved"
-	ServiceClaimStateInvalid   ServiceClaimState = "Invalid"
+	ServiceClaimConditionReady      ServiceClaimState = "Ready"
+	ServiceClaimConditionRanked     ServiceClaimState = "Ranked"
+	ServiceClaimConditionFailedOver ServiceClaimState = "FailedOver"
+	ServiceClaimConditionMatched    ServiceClaimState = "Matched"
+	ServiceClaimConditionCataloged  ServiceClaimState = "Cataloged"
+	ServiceClaimStatePending        ServiceClaimState = "Pending"
+	ServiceClaimStateResolved       ServiceClaimState = "Resolved"
+	ServiceClaimStateInvalid        ServiceClaimState = "Invalid"
+)
+
+type ServiceClaimFailoverPolicy string
+
+const (
+	ServiceClaimFailoverPolicyNever         ServiceClaimFailoverPolicy = "Never"
+	ServiceClaimFailoverPolicyOnUnreachable ServiceClaimFailoverPolicy = "OnUnreachable"
 )
 
 // ServiceClaimSpec defines the desired state of ServiceClaim
@@ -38,6 +49,12 @@ type ServiceClaimSpec struct {
 	// +required
 	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
 	ServiceClassIdentity []ServiceClassIdentityItem `json:"serviceClassIdentity"`
+	// ServiceClassIdentityExpressions defines a set of requirements on the
+	// attributes identifying a service class.  A RegisteredService can be
+	// claimed only if its ServiceClassIdentity satisfies all of them.
+	// +optional
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="Value is immutable"
+	ServiceClassIdentityExpressions []ServiceClassIdentityRequirement `json:"serviceClassIdentityExpressions,omitempty"`
 	// ServiceEndpointDefinition defines a set of attributes sufficient for a
 	// client to establish a connection to the service.
 	// +required
@@ -51,6 +68,18 @@ type ServiceClaimSpec struct {
 	// Envs allows projecting Service Endpoint Definition's data as Environment Variables in the Pod
 	// +optional
 	Envs []Environment `json:"envs,omitempty"`
+	// FailoverPolicy defines whether a Resolved ServiceClaim is bound to
+	// another matching RegisteredService when the claimed one becomes Unreachable
+	// +optional
+	//+kubebuilder:validation:Enum=Never;OnUnreachable
+	//+kubebuilder:default:=Never
+	FailoverPolicy ServiceClaimFailoverPolicy `json:"failoverPolicy,omitempty"`
+	// RestartPolicy defines whether bound workloads are restarted when the
+	// content of the Service Endpoint Definition secret changes
+	// +optional
+	//+kubebuilder:validation:Enum=Never;OnSecretChange
+	//+kubebuilder:default:=Never
+	RestartPolicy ServiceBindingRestartPolicy `json:"restartPolicy,omitempty"`
 }
 
 // The Service Claim target.
@@ -81,8 +110,33 @@ type ServiceClaimStatus struct {
 	ClaimID string `json:"claimID,omitempty"`
 	// Claimed RegisteredService Info
 	RegisteredService *corev1.ObjectReference `json:"registeredService,omitempty"`
+	// ResolvedVersion is the version of the claimed RegisteredService, when
+	// the ServiceClaim constrains it with a VersionRange expression
+	ResolvedVersion string `json:"resolvedVersion,omitempty"`
+	// SecretChecksum is the checksum of the Service Endpoint Definition
+	// secret last pushed to the application namespaces
+	SecretChecksum string `json:"secretChecksum,omitempty"`
+	// SecretRotationGeneration is increased each time a change in the
+	// secrets referenced by the claimed RegisteredService is propagated to
+	// the application namespaces
+	SecretRotationGeneration int64 `json:"secretRotationGeneration,omitempty"`
 	// The status of the service binding along with reason and type
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// DryRun reports the outcome of the resolution of a ServiceClaim
+	// annotated for dry run
+	DryRun *ServiceClaimDryRunResult `json:"dryRun,omitempty"`
+}
+
+// ServiceClaimDryRunResult reports what a ServiceClaim would be bound to
+type ServiceClaimDryRunResult struct {
+	// RegisteredService the ServiceClaim would be bound to
+	RegisteredService *corev1.ObjectReference `json:"registeredService,omitempty"`
+	// ResolvedVersion is the version of the RegisteredService, when the
+	// ServiceClaim constrains it with a VersionRange expression
+	ResolvedVersion string `json:"resolvedVersion,omitempty"`
+	// SecretKeys are the keys of the Secret that would be projected into
+	// the application
+	SecretKeys []string `json:"secretKeys,omitempty"`
 }
 
 //+kubebuilder:object:root=true
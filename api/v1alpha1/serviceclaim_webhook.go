@@ -0,0 +1,214 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var serviceclaimlog = logf.Log.WithName("serviceclaim-resource")
+
+type serviceClaimValidator struct {
+	client client.Client
+	// agent is true when the validator runs in an Application Agent.
+	// Application Agents fill the ServiceClaim's target when forwarding it
+	// to Primaza's Control Plane, so the target is not validated there.
+	agent bool
+}
+
+var _ admission.CustomValidator = &serviceClaimValidator{}
+
+// SetupWebhookWithManager registers the ServiceClaim webhook in Primaza's Control Plane
+func (r *ServiceClaim) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&serviceClaimValidator{
+			client: mgr.GetClient(),
+		}).
+		Complete()
+}
+
+// SetupAgentWebhookWithManager registers the ServiceClaim webhook in an Application Agent
+func (r *ServiceClaim) SetupAgentWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&serviceClaimValidator{
+			client: mgr.GetClient(),
+			agent:  true,
+		}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-primaza-io-v1alpha1-serviceclaim,mutating=false,failurePolicy=fail,sideEffects=None,groups=primaza.io,resources=serviceclaims,verbs=create;update,versions=v1alpha1,name=vserviceclaim.kb.io,admissionReviewVersions=v1
+
+// ValidateApplication checks that the workloads to bind are selected either
+// by name or by label selector
+func (r *ServiceClaimSpec) ValidateApplication() field.ErrorList {
+	errs := field.ErrorList{}
+	path := field.NewPath("spec", "application")
+	if r.Application.Name != "" && r.Application.Selector != nil {
+		errs = append(errs, field.Invalid(path.Child("selector"), r.Application.Selector, "name and selector are mutually exclusive"))
+	}
+	return errs
+}
+
+// ValidateTarget checks that either an EnvironmentTag or an
+// ApplicationClusterContext is defined
+func (r *ServiceClaimSpec) ValidateTarget() field.ErrorList {
+	errs := field.ErrorList{}
+	path := field.NewPath("spec", "target")
+	switch {
+	case r.Target == nil:
+		errs = append(errs, field.Required(path, "one of environmentTag or applicationClusterContext is required"))
+	case r.Target.EnvironmentTag == "" && r.Target.ApplicationClusterContext == nil:
+		errs = append(errs, field.Required(path, "one of environmentTag or applicationClusterContext is required"))
+	case r.Target.EnvironmentTag != "" && r.Target.ApplicationClusterContext != nil:
+		errs = append(errs, field.Invalid(path.Child("applicationClusterContext"), r.Target.ApplicationClusterContext, "environmentTag and applicationClusterContext are mutually exclusive"))
+	}
+	return errs
+}
+
+// ValidateEnvs checks that the environment variables only reference keys the
+// bound secret will contain, i.e. the ServiceEndpointDefinition keys and the
+// ServiceClassIdentity attributes
+func (r *ServiceClaimSpec) ValidateEnvs() field.ErrorList {
+	errs := field.ErrorList{}
+	keys := map[string]struct{}{}
+	for _, k := range r.ServiceEndpointDefinitionKeys {
+		keys[k] = struct{}{}
+	}
+	for _, sci := range r.ServiceClassIdentity {
+		keys[sci.Name] = struct{}{}
+	}
+
+	path := field.NewPath("spec", "envs")
+	for i, e := range r.Envs {
+		if _, found := keys[e.Key]; !found {
+			errs = append(errs, field.NotFound(path.Index(i).Child("key"), e.Key))
+		}
+	}
+	return errs
+}
+
+func (v *serviceClaimValidator) validate(ctx context.Context, r *ServiceClaim, checkClusterEnvironment bool) (field.ErrorList, error) {
+	errs := r.Spec.ValidateApplication()
+	errs = append(errs, r.Spec.ValidateEnvs()...)
+	if v.agent {
+		return errs, nil
+	}
+
+	terrs := r.Spec.ValidateTarget()
+	errs = append(errs, terrs...)
+	if len(terrs) != 0 || !checkClusterEnvironment || r.Spec.Target.ApplicationClusterContext == nil {
+		return errs, nil
+	}
+
+	cerrs, err := v.validateClusterEnvironment(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return append(errs, cerrs...), nil
+}
+
+func (v *serviceClaimValidator) validateClusterEnvironment(ctx context.Context, r *ServiceClaim) (field.ErrorList, error) {
+	acc := r.Spec.Target.ApplicationClusterContext
+	var ce ClusterEnvironment
+	k := types.NamespacedName{Namespace: r.Namespace, Name: acc.ClusterEnvironmentName}
+	if err := v.client.Get(ctx, k, &ce); err != nil {
+		if apierrors.IsNotFound(err) {
+			path := field.NewPath("spec", "target", "applicationClusterContext", "clusterEnvironmentName")
+			return field.ErrorList{field.NotFound(path, acc.ClusterEnvironmentName)}, nil
+		}
+		return nil, err
+	}
+	return nil, nil
+}
+
+// ValidateCreate implements admission.CustomValidator
+func (v *serviceClaimValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	r, ok := obj.(*ServiceClaim)
+	if !ok {
+		err := fmt.Errorf("Object is not a Service Claim")
+		serviceclaimlog.Error(err, "Attempted to validate non-ServiceClaim resource", "gvk", obj.GetObjectKind().GroupVersionKind())
+		return nil, err
+	}
+
+	serviceclaimlog.Info("validate create", "name", r.Name)
+	errs, err := v.validate(ctx, r, true)
+	if err != nil {
+		return nil, err
+	}
+	return nil, errs.ToAggregate()
+}
+
+// ValidateDelete implements admission.CustomValidator
+func (v *serviceClaimValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	r, ok := obj.(*ServiceClaim)
+	if !ok {
+		err := fmt.Errorf("Object is not a Service Claim")
+		serviceclaimlog.Error(err, "Attempted to validate non-ServiceClaim resource", "gvk", obj.GetObjectKind().GroupVersionKind())
+		return nil, err
+	}
+
+	serviceclaimlog.Info("validate delete", "name", r.Name)
+	return nil, nil // no validation
+}
+
+// ValidateUpdate implements admission.CustomValidator
+func (v *serviceClaimValidator) ValidateUpdate(ctx context.Context, oldObj runtime.Object, newObj runtime.Object) (admission.Warnings, error) {
+	newClaim, ok := newObj.(*ServiceClaim)
+	if !ok {
+		err := fmt.Errorf("Object is not a Service Claim")
+		serviceclaimlog.Error(err, "Attempted to validate non-ServiceClaim resource", "gvk", newObj.GetObjectKind().GroupVersionKind())
+		return nil, err
+	}
+
+	serviceclaimlog.Info("validate update", "name", newClaim.Name)
+
+	oldClaim, ok := oldObj.(*ServiceClaim)
+	if !ok {
+		return nil, fmt.Errorf("Old object is not a ServiceClaim")
+	}
+
+	// the ServiceClaim is being deleted, finalizers must be removable
+	// even if the ClusterEnvironment is gone
+	if newClaim.HasDeletionTimestamp() {
+		return nil, nil
+	}
+
+	// the existence of the ClusterEnvironment is only checked when the target
+	// changes, so that a ServiceClaim can still be updated after its
+	// ClusterEnvironment has been deleted
+	targetChanged := !reflect.DeepEqual(oldClaim.Spec.Target, newClaim.Spec.Target)
+	errs, err := v.validate(ctx, newClaim, targetChanged)
+	if err != nil {
+		return nil, err
+	}
+	return nil, errs.ToAggregate()
+}
@@ -0,0 +1,306 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newServiceClaim(name, namespace string, spec ServiceClaimSpec) ServiceClaim {
+	return ServiceClaim{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: spec,
+	}
+}
+
+var _ = Describe("ServiceClaim Webhook tests", func() {
+	var validator serviceClaimValidator
+	tr := func(_ []string, err error) error { return err }
+
+	BeforeEach(func() {
+		schemeBuilder, err := SchemeBuilder.Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		ce := ClusterEnvironment{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "ce",
+				Namespace: "primaza-system",
+			},
+		}
+		validator = serviceClaimValidator{
+			client: fake.NewClientBuilder().
+				WithScheme(schemeBuilder).
+				WithObjects(&ce).
+				Build(),
+		}
+	})
+
+	It("should accept valid service claims", func() {
+		sclaim := newServiceClaim("claim", "primaza-system", ServiceClaimSpec{
+			ServiceClassIdentity: []ServiceClassIdentityItem{
+				{Name: "type", Value: "psql"},
+			},
+			ServiceEndpointDefinitionKeys: []string{"host", "password"},
+			Application: ApplicationSelector{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "app",
+			},
+			Target: &ServiceClaimTarget{
+				ApplicationClusterContext: &ServiceClaimApplicationClusterContext{
+					ClusterEnvironmentName: "ce",
+					Namespace:              "applications",
+				},
+			},
+			Envs: []Environment{
+				{Name: "DB_HOST", Key: "host"},
+				{Name: "DB_TYPE", Key: "type"},
+			},
+		})
+		Expect(tr(validator.ValidateCreate(context.Background(), &sclaim))).NotTo(HaveOccurred())
+	})
+
+	DescribeTable("Creation validation failures",
+		func(sclaim ServiceClaim, expected field.ErrorList) {
+			_, err := validator.ValidateCreate(context.Background(), &sclaim)
+			Expect(err).To(Equal(expected.ToAggregate()))
+		},
+		Entry("Application name and selector",
+			newServiceClaim("claim", "primaza-system", ServiceClaimSpec{
+				ServiceClassIdentity: []ServiceClassIdentityItem{
+					{Name: "type", Value: "psql"},
+				},
+				ServiceEndpointDefinitionKeys: []string{"host", "password"},
+				Application: ApplicationSelector{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "app",
+					Selector:   &v1.LabelSelector{MatchLabels: map[string]string{"app": "app"}},
+				},
+				Target: &ServiceClaimTarget{
+					ApplicationClusterContext: &ServiceClaimApplicationClusterContext{
+						ClusterEnvironmentName: "ce",
+						Namespace:              "applications",
+					},
+				},
+			}),
+			field.ErrorList{
+				field.Invalid(field.NewPath("spec", "application", "selector"),
+					&v1.LabelSelector{MatchLabels: map[string]string{"app": "app"}},
+					"name and selector are mutually exclusive"),
+			}),
+		Entry("Missing target",
+			newServiceClaim("claim", "primaza-system", ServiceClaimSpec{
+				ServiceClassIdentity: []ServiceClassIdentityItem{
+					{Name: "type", Value: "psql"},
+				},
+				ServiceEndpointDefinitionKeys: []string{"host", "password"},
+				Application: ApplicationSelector{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "app",
+				},
+			}),
+			field.ErrorList{
+				field.Required(field.NewPath("spec", "target"), "one of environmentTag or applicationClusterContext is required"),
+			}),
+		Entry("Empty target",
+			newServiceClaim("claim", "primaza-system", ServiceClaimSpec{
+				ServiceClassIdentity: []ServiceClassIdentityItem{
+					{Name: "type", Value: "psql"},
+				},
+				ServiceEndpointDefinitionKeys: []string{"host", "password"},
+				Application: ApplicationSelector{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "app",
+				},
+				Target: &ServiceClaimTarget{},
+			}),
+			field.ErrorList{
+				field.Required(field.NewPath("spec", "target"), "one of environmentTag or applicationClusterContext is required"),
+			}),
+		Entry("Envs referencing unknown keys",
+			newServiceClaim("claim", "primaza-system", ServiceClaimSpec{
+				ServiceClassIdentity: []ServiceClassIdentityItem{
+					{Name: "type", Value: "psql"},
+				},
+				ServiceEndpointDefinitionKeys: []string{"host", "password"},
+				Application: ApplicationSelector{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "app",
+				},
+				Target: &ServiceClaimTarget{
+					ApplicationClusterContext: &ServiceClaimApplicationClusterContext{
+						ClusterEnvironmentName: "ce",
+						Namespace:              "applications",
+					},
+				},
+				Envs: []Environment{
+					{Name: "DB_HOST", Key: "host"},
+					{Name: "DB_PORT", Key: "port"},
+				},
+			}),
+			field.ErrorList{
+				field.NotFound(field.NewPath("spec", "envs").Index(1).Child("key"), "port"),
+			}),
+		Entry("Non-existent ClusterEnvironment",
+			newServiceClaim("claim", "primaza-system", ServiceClaimSpec{
+				ServiceClassIdentity: []ServiceClassIdentityItem{
+					{Name: "type", Value: "psql"},
+				},
+				ServiceEndpointDefinitionKeys: []string{"host", "password"},
+				Application: ApplicationSelector{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "app",
+				},
+				Target: &ServiceClaimTarget{
+					ApplicationClusterContext: &ServiceClaimApplicationClusterContext{
+						ClusterEnvironmentName: "missing",
+						Namespace:              "applications",
+					},
+				},
+			}),
+			field.ErrorList{
+				field.NotFound(field.NewPath("spec", "target", "applicationClusterContext", "clusterEnvironmentName"), "missing"),
+			}),
+		Entry("ClusterEnvironment in another namespace",
+			newServiceClaim("claim", "other", ServiceClaimSpec{
+				ServiceClassIdentity: []ServiceClassIdentityItem{
+					{Name: "type", Value: "psql"},
+				},
+				ServiceEndpointDefinitionKeys: []string{"host", "password"},
+				Application: ApplicationSelector{
+					APIVersion: "apps/v1",
+					Kind:       "Deployment",
+					Name:       "app",
+				},
+				Target: &ServiceClaimTarget{
+					ApplicationClusterContext: &ServiceClaimApplicationClusterContext{
+						ClusterEnvironmentName: "ce",
+						Namespace:              "applications",
+					},
+				},
+			}),
+			field.ErrorList{
+				field.NotFound(field.NewPath("spec", "target", "applicationClusterContext", "clusterEnvironmentName"), "ce"),
+			}),
+	)
+
+	It("should not check the ClusterEnvironment when the target is unchanged", func() {
+		sclaim := newServiceClaim("claim", "primaza-system", ServiceClaimSpec{
+			ServiceClassIdentity: []ServiceClassIdentityItem{
+				{Name: "type", Value: "psql"},
+			},
+			ServiceEndpointDefinitionKeys: []string{"host", "password"},
+			Application: ApplicationSelector{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "app",
+			},
+			Target: &ServiceClaimTarget{
+				ApplicationClusterContext: &ServiceClaimApplicationClusterContext{
+					ClusterEnvironmentName: "deleted",
+					Namespace:              "applications",
+				},
+			},
+		})
+		updated := sclaim.DeepCopy()
+		updated.Spec.Envs = []Environment{{Name: "DB_HOST", Key: "host"}}
+
+		Expect(tr(validator.ValidateUpdate(context.Background(), &sclaim, updated))).NotTo(HaveOccurred())
+
+		updated.Spec.Target.ApplicationClusterContext.ClusterEnvironmentName = "missing"
+		Expect(tr(validator.ValidateUpdate(context.Background(), &sclaim, updated))).To(HaveOccurred())
+	})
+
+	It("should allow updates of service claims being deleted", func() {
+		sclaim := newServiceClaim("claim", "primaza-system", ServiceClaimSpec{
+			ServiceClassIdentity: []ServiceClassIdentityItem{
+				{Name: "type", Value: "psql"},
+			},
+			ServiceEndpointDefinitionKeys: []string{"host", "password"},
+			Application: ApplicationSelector{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "app",
+			},
+		})
+		updated := sclaim.DeepCopy()
+		now := v1.Now()
+		updated.DeletionTimestamp = &now
+
+		Expect(tr(validator.ValidateUpdate(context.Background(), &sclaim, updated))).NotTo(HaveOccurred())
+	})
+
+	It("should not validate the target in application agents", func() {
+		validator.agent = true
+		sclaim := newServiceClaim("claim", "applications", ServiceClaimSpec{
+			ServiceClassIdentity: []ServiceClassIdentityItem{
+				{Name: "type", Value: "psql"},
+			},
+			ServiceEndpointDefinitionKeys: []string{"host", "password"},
+			Application: ApplicationSelector{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "app",
+			},
+		})
+		Expect(tr(validator.ValidateCreate(context.Background(), &sclaim))).NotTo(HaveOccurred())
+
+		sclaim.Spec.Envs = []Environment{{Name: "DB_PORT", Key: "port"}}
+		Expect(tr(validator.ValidateCreate(context.Background(), &sclaim))).To(HaveOccurred())
+	})
+
+	It("should reject non-ServiceClaim objects", func() {
+		oldObject := unstructured.Unstructured{}
+		newObject := newServiceClaim("claim", "primaza-system", ServiceClaimSpec{
+			ServiceClassIdentity: []ServiceClassIdentityItem{
+				{Name: "type", Value: "psql"},
+			},
+			ServiceEndpointDefinitionKeys: []string{"host", "password"},
+			Application: ApplicationSelector{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "app",
+			},
+			Target: &ServiceClaimTarget{
+				ApplicationClusterContext: &ServiceClaimApplicationClusterContext{
+					ClusterEnvironmentName: "ce",
+					Namespace:              "applications",
+				},
+			},
+		})
+
+		Expect(tr(validator.ValidateCreate(context.Background(), &oldObject))).To(HaveOccurred())
+		Expect(tr(validator.ValidateUpdate(context.Background(), &oldObject, &newObject))).To(HaveOccurred())
+		Expect(tr(validator.ValidateUpdate(context.Background(), &newObject, &oldObject))).To(HaveOccurred())
+		Expect(tr(validator.ValidateDelete(context.Background(), &oldObject))).To(HaveOccurred())
+	})
+})
@@ -23,6 +23,14 @@ import (
 type ServiceEndpointDefinitionMappings struct {
 	ResourceFields  []ServiceClassResourceFieldMapping  `json:"resourceFields,omitempty"`
 	SecretRefFields []ServiceClassSecretRefFieldMapping `json:"secretRefFields,omitempty"`
+	// +optional
+	ConfigMapRefFields []ServiceClassConfigMapRefFieldMapping `json:"configMapRefFields,omitempty"`
+	// +optional
+	ProviderRefFields []ServiceClassProviderRefFieldMapping `json:"providerRefFields,omitempty"`
+	// TemplateFields compose new keys from the values of the keys defined
+	// by ResourceFields, SecretRefFields, and ConfigMapRefFields
+	// +optional
+	TemplateFields []ServiceClassTemplateFieldMapping `json:"templateFields,omitempty"`
 }
 
 type ServiceClassResourceFieldMapping struct {
@@ -30,13 +38,23 @@ type ServiceClassResourceFieldMapping struct {
 	Name string `json:"name"`
 
 	// JsonPath defines where data lives in the service resource.  This query
-	// must resolve to a single value (e.g. not an array of values).
-	JsonPath string `json:"jsonPath"`
+	// must resolve to a single value (e.g. not an array of values).  It is
+	// mutually exclusive with Expression.
+	// +optional
+	JsonPath string `json:"jsonPath,omitempty"`
+
+	// Expression is a CEL expression extracting data from the service
+	// resource, that is bound to the `self` variable.  The expression must
+	// evaluate to a scalar value.  It is mutually exclusive with JsonPath.
+	// +optional
+	Expression string `json:"expression,omitempty"`
 
 	// Secret indicates whether or not the mapping data needs to be stored in a secret.
 	// +optional
 	// +kubebuilder:default=true
 	Secret bool `json:"secret"`
+
+	OptionalMapping `json:",inline"`
 }
 
 type ServiceClassSecretRefFieldMapping struct {
@@ -50,6 +68,76 @@ type ServiceClassSecretRefFieldMapping struct {
 	// SecretKey defines a constant value or a JsonPath used to extract from
 	// resource's specification the Key to be copied from the linked secret
 	SecretKey FieldMapping `json:"secretKey"`
+
+	// SecretNamespace defines a constant value or a JsonPath used to extract
+	// from resource's specification the namespace of the linked secret.  It
+	// defaults to the ServiceClass's namespace, and it is needed to read
+	// secrets linked to cluster-scoped resources.
+	// +optional
+	SecretNamespace *FieldMapping `json:"secretNamespace,omitempty"`
+
+	OptionalMapping `json:",inline"`
+}
+
+type ServiceClassConfigMapRefFieldMapping struct {
+	// Name of the data referred to
+	Name string `json:"name"`
+
+	// ConfigMapName defines a constant value or a JsonPath used to extract from
+	// resource's specification the name of a linked config map
+	ConfigMapName FieldMapping `json:"configMapName"`
+
+	// ConfigMapKey defines a constant value or a JsonPath used to extract from
+	// resource's specification the Key to be copied from the linked config map
+	ConfigMapKey FieldMapping `json:"configMapKey"`
+
+	OptionalMapping `json:",inline"`
+}
+
+type ServiceClassProviderRefFieldMapping struct {
+	// Name of the data referred to
+	Name string `json:"name"`
+
+	// Provider is the name of the provider resolving the value from an
+	// external store
+	Provider string `json:"provider"`
+
+	// Key defines a constant value or a JsonPath used to extract from
+	// resource's specification the Key of the value in the provider's store
+	Key FieldMapping `json:"key"`
+}
+
+// OptionalMapping defines how a mapping that can not be resolved is handled
+type OptionalMapping struct {
+	// Optional indicates whether the key can be omitted from the Service
+	// Endpoint Definition when the mapping can not be resolved
+	// +optional
+	Optional bool `json:"optional,omitempty"`
+
+	// Default is the value used when the mapping can not be resolved.
+	// It implies Optional.
+	// +optional
+	Default string `json:"default,omitempty"`
+}
+
+// IsOptional returns true if the key can be omitted or defaulted
+func (m OptionalMapping) IsOptional() bool {
+	return m.Optional || m.Default != ""
+}
+
+type ServiceClassTemplateFieldMapping struct {
+	// Name of the data referred to
+	Name string `json:"name"`
+
+	// Template is a Go template rendering the value.  The values of the keys
+	// defined by ResourceFields, SecretRefFields, and ConfigMapRefFields are
+	// available as fields of the template's data (e.g. `{{ .host }}`).
+	Template string `json:"template"`
+
+	// Secret indicates whether or not the mapping data needs to be stored in a secret.
+	// +optional
+	// +kubebuilder:default=true
+	Secret bool `json:"secret"`
 }
 
 // +kubebuilder:validation:MaxProperties:=1
@@ -59,6 +147,9 @@ type FieldMapping struct {
 	Constant *string `json:"constant,omitempty"`
 	// JsonPathExpr represents a jsonPath for extracting the field
 	JsonPathExpr *string `json:"jsonPath,omitempty"`
+	// Expression is a CEL expression for extracting the field from the
+	// resource, that is bound to the `self` variable
+	Expression *string `json:"expression,omitempty"`
 }
 
 // ServiceClassResource defines
@@ -69,11 +160,60 @@ type ServiceClassResource struct {
 	// Kind of the underlying service resource
 	Kind string `json:"kind"`
 
+	// LabelSelector restricts the resources registered by the ServiceClass
+	// to the ones matching the selector
+	// +optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+
+	// FieldSelector restricts the resources registered by the ServiceClass
+	// to the ones matching the field selector (e.g. `metadata.name=orders`)
+	// +optional
+	FieldSelector string `json:"fieldSelector,omitempty"`
+
 	// ServiceEndpointDefinitionMappings defines how a key-value mapping projected
 	// into services may be constructed.
+	// +optional
 	ServiceEndpointDefinitionMappings ServiceEndpointDefinitionMappings `json:"serviceEndpointDefinitionMappings"`
+
+	// ProvisionedService marks the resources as implementing the
+	// servicebinding.io ProvisionedService duck type.  Every key of the
+	// secret referenced by `status.binding.name` is added to the Service
+	// Endpoint Definition, unless a mapping defines the same key.  Resources
+	// that have not published their binding secret yet are not ready.
+	// +optional
+	ProvisionedService bool `json:"provisionedService,omitempty"`
+
+	// ReadinessGate defines when a resource is ready to be registered.  Until
+	// it is satisfied, no Registered Service is published for the resource.
+	// +optional
+	ReadinessGate *ServiceClassReadinessGate `json:"readinessGate,omitempty"`
 }
 
+// ServiceClassReadinessGate defines when a resource is ready to be registered.
+// Exactly one of ConditionType and Expression must be set.
+// +kubebuilder:validation:MaxProperties:=1
+// +kubebuilder:validation:MinProperties:=1
+type ServiceClassReadinessGate struct {
+	// ConditionType is the type of the resource's status condition that
+	// must be True
+	// +optional
+	ConditionType string `json:"conditionType,omitempty"`
+
+	// Expression is a CEL expression, bound to the `self` variable, that
+	// must evaluate to true
+	// +optional
+	Expression string `json:"expression,omitempty"`
+}
+
+// ServiceClassIncompleteResourcePolicy defines how resources missing
+// required Service Endpoint Definition keys are handled
+type ServiceClassIncompleteResourcePolicy string
+
+const (
+	ServiceClassIncompleteResourcePolicySkip     ServiceClassIncompleteResourcePolicy = "Skip"
+	ServiceClassIncompleteResourcePolicyRegister ServiceClassIncompleteResourcePolicy = "Register"
+)
+
 // ServiceClassSpec defines the desired state of ServiceClass
 type ServiceClassSpec struct {
 	// Constraints defines under which circumstances the ServiceClass may
@@ -89,12 +229,42 @@ type ServiceClassSpec struct {
 	// Services
 	Resource ServiceClassResource `json:"resource"`
 
+	// AdditionalResources defines further resource types to be converted
+	// into Registered Services with the same ServiceClassIdentity.  Each
+	// resource type has its own mappings.
+	// +optional
+	AdditionalResources []ServiceClassResource `json:"additionalResources,omitempty"`
+
+	// IncompleteResourcePolicy defines whether resources missing required
+	// Service Endpoint Definition keys are skipped or registered with the
+	// missing keys and state Unknown
+	// +optional
+	//+kubebuilder:validation:Enum=Skip;Register
+	//+kubebuilder:default:=Skip
+	IncompleteResourcePolicy ServiceClassIncompleteResourcePolicy `json:"incompleteResourcePolicy,omitempty"`
+
 	// ServiceClassIdentity defines a set of attributes that are sufficient to
 	// identify a service class.  A ServiceClaim whose ServiceClassIdentity
 	// field is a subset of a RegisteredService's keys can claim that service.
 	ServiceClassIdentity []ServiceClassIdentityItem `json:"serviceClassIdentity"`
 }
 
+// GetResources returns all the resource types of the ServiceClass
+func (s ServiceClassSpec) GetResources() []ServiceClassResource {
+	return append([]ServiceClassResource{s.Resource}, s.AdditionalResources...)
+}
+
+// GetResource returns the resource type of the ServiceClass with the given
+// APIVersion and Kind, if any
+func (s ServiceClassSpec) GetResource(apiVersion, kind string) *ServiceClassResource {
+	for _, r := range s.GetResources() {
+		if r.APIVersion == apiVersion && r.Kind == kind {
+			return &r
+		}
+	}
+	return nil
+}
+
 func (s ServiceClassSpec) GetEnvironmentConstraints() []string {
 	if s.Constraints != nil {
 		return s.Constraints.Environments
@@ -103,6 +273,12 @@ func (s ServiceClassSpec) GetEnvironmentConstraints() []string {
 	return nil
 }
 
+const (
+	// ServiceClassMappedCondition means the ServiceEndpointDefinitionMappings
+	// have been successfully evaluated for all the ServiceClass's resources
+	ServiceClassMappedCondition = "Mapped"
+)
+
 // ServiceClassStatus defines the observed state of ServiceClass
 type ServiceClassStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
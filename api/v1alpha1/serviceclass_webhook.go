@@ -19,8 +19,9 @@ package v1alpha1
 import (
 	"context"
 	"fmt"
-	"reflect"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/util/jsonpath"
@@ -28,6 +29,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/primaza/primaza/pkg/celexpr"
 )
 
 // log is for logging in this package.
@@ -51,16 +54,38 @@ func (r *ServiceClass) SetupWebhookWithManager(mgr ctrl.Manager) error {
 // TODO(user): change verbs to "verbs=create;update;delete" if you want to enable deletion validation.
 //+kubebuilder:webhook:path=/validate-primaza-io-v1alpha1-serviceclass,mutating=false,failurePolicy=fail,sideEffects=None,groups=primaza.io,resources=serviceclasses,verbs=create;update,versions=v1alpha1,name=vserviceclass.kb.io,admissionReviewVersions=v1
 
-func (r *ServiceClassResource) ValidateMapping() field.ErrorList {
+// validateResources validates all the resource types of the ServiceClass
+func (s *ServiceClassSpec) validateResources() field.ErrorList {
+	errs := s.Resource.ValidateMapping(field.NewPath("spec", "resource"))
+
+	gvks := map[string]struct{}{s.Resource.Kind + "." + s.Resource.APIVersion: {}}
+	for i, r := range s.AdditionalResources {
+		path := field.NewPath("spec", "additionalResources").Index(i)
+		gvk := r.Kind + "." + r.APIVersion
+		if _, found := gvks[gvk]; found {
+			errs = append(errs, field.Duplicate(path, gvk))
+		} else {
+			gvks[gvk] = struct{}{}
+		}
+		errs = append(errs, r.ValidateMapping(path)...)
+	}
+	return errs
+}
+
+func (r *ServiceClassResource) ValidateMapping(childPath *field.Path) field.ErrorList {
 	errs := field.ErrorList{}
 	names := map[string]struct{}{}
-	childPath := field.NewPath("spec", "resource")
 	for i, mapping := range r.ServiceEndpointDefinitionMappings.ResourceFields {
 		path := childPath.Child("serviceEndpointDefinitionMapping").Index(i)
-		j := jsonpath.New("")
-		formatted := fmt.Sprintf("{%v}", mapping.JsonPath)
-		if err := j.Parse(formatted); err != nil {
-			errs = append(errs, field.Invalid(path.Child("jsonPath"), mapping.JsonPath, "Invalid JSONPath"))
+		switch {
+		case mapping.Expression != "" && mapping.JsonPath != "":
+			errs = append(errs, field.Invalid(path.Child("expression"), mapping.Expression, "jsonPath and expression are mutually exclusive"))
+		case mapping.Expression != "":
+			errs = append(errs, validateExpression(path.Child("expression"), mapping.Expression)...)
+		case mapping.JsonPath == "":
+			errs = append(errs, field.Required(path.Child("jsonPath"), "one of jsonPath and expression is required"))
+		default:
+			errs = append(errs, validateJsonPath(path.Child("jsonPath"), mapping.JsonPath)...)
 		}
 		if _, found := names[mapping.Name]; found {
 			errs = append(errs, field.Duplicate(path.Child("name"), mapping.Name))
@@ -69,9 +94,95 @@ func (r *ServiceClassResource) ValidateMapping() field.ErrorList {
 		}
 	}
 
+	for i, mapping := range r.ServiceEndpointDefinitionMappings.SecretRefFields {
+		path := childPath.Child("serviceEndpointDefinitionMappings", "secretRefFields").Index(i)
+		errs = append(errs, validateFieldMapping(path.Child("secretName"), mapping.SecretName)...)
+		errs = append(errs, validateFieldMapping(path.Child("secretKey"), mapping.SecretKey)...)
+		if mapping.SecretNamespace != nil {
+			errs = append(errs, validateFieldMapping(path.Child("secretNamespace"), *mapping.SecretNamespace)...)
+		}
+	}
+
+	for i, mapping := range r.ServiceEndpointDefinitionMappings.ConfigMapRefFields {
+		path := childPath.Child("serviceEndpointDefinitionMappings", "configMapRefFields").Index(i)
+		errs = append(errs, validateFieldMapping(path.Child("configMapName"), mapping.ConfigMapName)...)
+		errs = append(errs, validateFieldMapping(path.Child("configMapKey"), mapping.ConfigMapKey)...)
+	}
+
+	for i, mapping := range r.ServiceEndpointDefinitionMappings.TemplateFields {
+		path := childPath.Child("serviceEndpointDefinitionMappings", "templateFields").Index(i)
+		if mapping.Template == "" {
+			errs = append(errs, field.Required(path.Child("template"), "template is required"))
+		}
+		if _, found := names[mapping.Name]; found {
+			errs = append(errs, field.Duplicate(path.Child("name"), mapping.Name))
+		} else {
+			names[mapping.Name] = struct{}{}
+		}
+	}
+
+	for i, mapping := range r.ServiceEndpointDefinitionMappings.ProviderRefFields {
+		path := childPath.Child("serviceEndpointDefinitionMappings", "providerRefFields").Index(i)
+		if mapping.Provider == "" {
+			errs = append(errs, field.Required(path.Child("provider"), "provider name is required"))
+		}
+		errs = append(errs, validateFieldMapping(path.Child("key"), mapping.Key)...)
+	}
+
+	if g := r.ReadinessGate; g != nil {
+		path := childPath.Child("readinessGate")
+		switch {
+		case g.ConditionType != "" && g.Expression != "":
+			errs = append(errs, field.Invalid(path.Child("expression"), g.Expression, "conditionType and expression are mutually exclusive"))
+		case g.Expression != "":
+			errs = append(errs, validateExpression(path.Child("expression"), g.Expression)...)
+		case g.ConditionType == "":
+			errs = append(errs, field.Required(path.Child("conditionType"), "one of conditionType and expression is required"))
+		}
+	}
+
+	if r.LabelSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(r.LabelSelector); err != nil {
+			errs = append(errs, field.Invalid(childPath.Child("labelSelector"), r.LabelSelector, fmt.Sprintf("Invalid label selector: %v", err)))
+		}
+	}
+	if r.FieldSelector != "" {
+		if _, err := fields.ParseSelector(r.FieldSelector); err != nil {
+			errs = append(errs, field.Invalid(childPath.Child("fieldSelector"), r.FieldSelector, fmt.Sprintf("Invalid field selector: %v", err)))
+		}
+	}
+
 	return errs
 }
 
+func validateJsonPath(path *field.Path, jsonPath string) field.ErrorList {
+	j := jsonpath.New("")
+	formatted := fmt.Sprintf("{%v}", jsonPath)
+	if err := j.Parse(formatted); err != nil {
+		return field.ErrorList{field.Invalid(path, jsonPath, "Invalid JSONPath")}
+	}
+	return nil
+}
+
+// validateExpression type-checks a CEL expression
+func validateExpression(path *field.Path, expression string) field.ErrorList {
+	if _, err := celexpr.Compile(expression); err != nil {
+		return field.ErrorList{field.Invalid(path, expression, fmt.Sprintf("Invalid CEL expression: %v", err))}
+	}
+	return nil
+}
+
+func validateFieldMapping(path *field.Path, mapping FieldMapping) field.ErrorList {
+	switch {
+	case mapping.JsonPathExpr != nil:
+		return validateJsonPath(path.Child("jsonPath"), *mapping.JsonPathExpr)
+	case mapping.Expression != nil:
+		return validateExpression(path.Child("expression"), *mapping.Expression)
+	default:
+		return nil
+	}
+}
+
 // ValidateCreate implements admission.CustomValidator
 func (v *serviceClassValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
 	r, ok := obj.(*ServiceClass)
@@ -86,7 +197,7 @@ func (v *serviceClassValidator) ValidateCreate(ctx context.Context, obj runtime.
 	if err != nil {
 		return nil, err
 	}
-	errs = append(errs, r.Spec.Resource.ValidateMapping()...)
+	errs = append(errs, r.Spec.validateResources()...)
 	return nil, errs.ToAggregate()
 }
 
@@ -127,22 +238,9 @@ func (v *serviceClassValidator) ValidateUpdate(ctx context.Context, oldObj runti
 	if oldServiceClass.Spec.Resource.Kind != newClass.Spec.Resource.Kind {
 		errs = append(errs, field.Invalid(childPath.Child("kind"), newClass.Spec.Resource.Kind, "Kind is immutable"))
 	}
-	// a cheap way of doing data sets; struct{} is zero-sized, so we don't needlessly make allocations
-	oldMappings := map[ServiceClassResourceFieldMapping]struct{}{}
-	newMappings := map[ServiceClassResourceFieldMapping]struct{}{}
-	for _, item := range oldServiceClass.Spec.Resource.ServiceEndpointDefinitionMappings.ResourceFields {
-		oldMappings[item] = struct{}{}
-	}
-	for _, item := range newClass.Spec.Resource.ServiceEndpointDefinitionMappings.ResourceFields {
-		newMappings[item] = struct{}{}
-	}
-	if !reflect.DeepEqual(oldMappings, newMappings) {
-		errs = append(errs,
-			field.Invalid(childPath.Child("serviceEndpointDefinitionMapping"),
-				newClass.Spec.Resource.ServiceEndpointDefinitionMappings,
-				"ServiceEndpointDefinitionMapping is immutable"))
-	}
-	errs = append(errs, newClass.Spec.Resource.ValidateMapping()...)
+	// ServiceEndpointDefinitionMappings can be updated: the service agents
+	// regenerate the RegisteredServices in place
+	errs = append(errs, newClass.Spec.validateResources()...)
 	list, err := v.IsDuplicateClass(ctx, *newClass)
 	if err != nil {
 		return nil, err
@@ -162,16 +260,23 @@ func (validator *serviceClassValidator) IsDuplicateClass(ctx context.Context, se
 
 	serviceclasslog.Info("checking items", "items", classList)
 	for _, item := range classList.Items {
-		if serviceClass.Name != item.Name &&
-			serviceClass.Spec.Resource.Kind == item.Spec.Resource.Kind &&
-			serviceClass.Spec.Resource.APIVersion == item.Spec.Resource.APIVersion {
-			// We found another ServiceClass that manages the same kind/apiVersion in this namespace, so report it as a match.
-			return field.ErrorList{
-				field.Forbidden(field.NewPath("spec", "resource"),
-					fmt.Sprintf("Service Class %v already manages services of type %v.%v",
-						item.Name,
-						item.Spec.Resource.Kind,
-						item.Spec.Resource.APIVersion))}, nil
+		if serviceClass.Name == item.Name {
+			continue
+		}
+		for i, resource := range serviceClass.Spec.GetResources() {
+			if r := item.Spec.GetResource(resource.APIVersion, resource.Kind); r != nil {
+				path := field.NewPath("spec", "resource")
+				if i > 0 {
+					path = field.NewPath("spec", "additionalResources").Index(i - 1)
+				}
+				// We found another ServiceClass that manages the same kind/apiVersion in this namespace, so report it as a match.
+				return field.ErrorList{
+					field.Forbidden(path,
+						fmt.Sprintf("Service Class %v already manages services of type %v.%v",
+							item.Name,
+							r.Kind,
+							r.APIVersion))}, nil
+			}
 		}
 	}
 
@@ -24,6 +24,7 @@ import (
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
@@ -115,18 +116,7 @@ var _ = Describe("Webhook tests", func() {
 					field.Duplicate(field.NewPath("spec", "resource", "serviceEndpointDefinitionMapping").Index(1).Child("name"), "x"),
 				}.ToAggregate(),
 			}),
-	)
-
-	DescribeTable("Update validation failures",
-		func(oldClass, newClass ServiceClass, expected validationResult) {
-			w, err := validator.ValidateUpdate(context.Background(), &oldClass, &newClass)
-			obtained := validationResult{
-				warnings: w,
-				err:      err,
-			}
-			Expect(obtained).To(Equal(expected))
-		},
-		Entry("Resource Kind is immutable",
+		Entry("Invalid expressions",
 			newServiceClass("spam", "eggs",
 				ServiceClassSpec{
 					Resource: ServiceClassResource{
@@ -135,18 +125,34 @@ var _ = Describe("Webhook tests", func() {
 						ServiceEndpointDefinitionMappings: ServiceEndpointDefinitionMappings{
 							ResourceFields: []ServiceClassResourceFieldMapping{
 								{
-									Name:     "x",
-									JsonPath: ".spec",
+									Name:       "x",
+									JsonPath:   ".spec.x",
+									Expression: "self.spec.x",
+								},
+								{
+									Name: "y",
+								},
+								{
+									Name:       "z",
+									Expression: "self.spec.z",
 								},
 							},
 						},
 					},
-				}),
+				},
+			),
+			validationResult{
+				err: field.ErrorList{
+					field.Invalid(field.NewPath("spec", "resource", "serviceEndpointDefinitionMapping").Index(0).Child("expression"), "self.spec.x", "jsonPath and expression are mutually exclusive"),
+					field.Required(field.NewPath("spec", "resource", "serviceEndpointDefinitionMapping").Index(1).Child("jsonPath"), "one of jsonPath and expression is required"),
+				}.ToAggregate(),
+			}),
+		Entry("Invalid template fields",
 			newServiceClass("spam", "eggs",
 				ServiceClassSpec{
 					Resource: ServiceClassResource{
 						APIVersion: "foo.bar/v1",
-						Kind:       "bam",
+						Kind:       "baz",
 						ServiceEndpointDefinitionMappings: ServiceEndpointDefinitionMappings{
 							ResourceFields: []ServiceClassResourceFieldMapping{
 								{
@@ -154,15 +160,151 @@ var _ = Describe("Webhook tests", func() {
 									JsonPath: ".spec",
 								},
 							},
+							TemplateFields: []ServiceClassTemplateFieldMapping{
+								{
+									Name:     "x",
+									Template: "{{ .x }}",
+								},
+								{
+									Name: "y",
+								},
+							},
 						},
 					},
-				}),
+				},
+			),
 			validationResult{
 				err: field.ErrorList{
-					field.Invalid(field.NewPath("spec", "resource", "kind"), "bam", "Kind is immutable"),
+					field.Duplicate(field.NewPath("spec", "resource", "serviceEndpointDefinitionMappings", "templateFields").Index(0).Child("name"), "x"),
+					field.Required(field.NewPath("spec", "resource", "serviceEndpointDefinitionMappings", "templateFields").Index(1).Child("template"), "template is required"),
 				}.ToAggregate(),
 			}),
-		Entry("Resource APIVersion is immutable",
+		Entry("Readiness gate with both condition type and expression",
+			newServiceClass("spam", "eggs",
+				ServiceClassSpec{
+					Resource: ServiceClassResource{
+						APIVersion: "foo.bar/v1",
+						Kind:       "baz",
+						ReadinessGate: &ServiceClassReadinessGate{
+							ConditionType: "Ready",
+							Expression:    "self.status.ready",
+						},
+					},
+				},
+			),
+			validationResult{
+				err: field.ErrorList{
+					field.Invalid(field.NewPath("spec", "resource", "readinessGate", "expression"), "self.status.ready", "conditionType and expression are mutually exclusive"),
+				}.ToAggregate(),
+			}),
+		Entry("Empty readiness gate",
+			newServiceClass("spam", "eggs",
+				ServiceClassSpec{
+					Resource: ServiceClassResource{
+						APIVersion:    "foo.bar/v1",
+						Kind:          "baz",
+						ReadinessGate: &ServiceClassReadinessGate{},
+					},
+				},
+			),
+			validationResult{
+				err: field.ErrorList{
+					field.Required(field.NewPath("spec", "resource", "readinessGate", "conditionType"), "one of conditionType and expression is required"),
+				}.ToAggregate(),
+			}),
+		Entry("Invalid secret namespace",
+			newServiceClass("spam", "eggs",
+				ServiceClassSpec{
+					Resource: ServiceClassResource{
+						APIVersion: "foo.bar/v1",
+						Kind:       "baz",
+						ServiceEndpointDefinitionMappings: ServiceEndpointDefinitionMappings{
+							SecretRefFields: []ServiceClassSecretRefFieldMapping{
+								{
+									Name:            "password",
+									SecretName:      FieldMapping{Constant: pointer.String("orders-db")},
+									SecretKey:       FieldMapping{Constant: pointer.String("password")},
+									SecretNamespace: &FieldMapping{JsonPathExpr: pointer.String(".spec.invalid[*")},
+								},
+							},
+						},
+					},
+				},
+			),
+			validationResult{
+				err: field.ErrorList{
+					field.Invalid(field.NewPath("spec", "resource", "serviceEndpointDefinitionMappings", "secretRefFields").Index(0).Child("secretNamespace", "jsonPath"), ".spec.invalid[*", "Invalid JSONPath"),
+				}.ToAggregate(),
+			}),
+		Entry("Invalid additional resources",
+			newServiceClass("spam", "eggs",
+				ServiceClassSpec{
+					Resource: ServiceClassResource{
+						APIVersion: "foo.bar/v1",
+						Kind:       "baz",
+					},
+					AdditionalResources: []ServiceClassResource{
+						{
+							APIVersion: "foo.bar/v1",
+							Kind:       "bam",
+							ServiceEndpointDefinitionMappings: ServiceEndpointDefinitionMappings{
+								ResourceFields: []ServiceClassResourceFieldMapping{
+									{
+										Name:     "x",
+										JsonPath: ".invalid[*",
+									},
+								},
+							},
+						},
+						{
+							APIVersion: "foo.bar/v1",
+							Kind:       "baz",
+						},
+					},
+				},
+			),
+			validationResult{
+				err: field.ErrorList{
+					field.Invalid(field.NewPath("spec", "additionalResources").Index(0).Child("serviceEndpointDefinitionMapping").Index(0).Child("jsonPath"), ".invalid[*", "Invalid JSONPath"),
+					field.Duplicate(field.NewPath("spec", "additionalResources").Index(1), "baz.foo.bar/v1"),
+				}.ToAggregate(),
+			}),
+		Entry("Invalid selectors",
+			newServiceClass("spam", "eggs",
+				ServiceClassSpec{
+					Resource: ServiceClassResource{
+						APIVersion: "foo.bar/v1",
+						Kind:       "baz",
+						LabelSelector: &v1.LabelSelector{
+							MatchExpressions: []v1.LabelSelectorRequirement{{Key: "tier", Operator: "Bogus"}},
+						},
+						FieldSelector: "metadata.name",
+					},
+				},
+			),
+			validationResult{
+				err: field.ErrorList{
+					field.Invalid(field.NewPath("spec", "resource", "labelSelector"),
+						&v1.LabelSelector{
+							MatchExpressions: []v1.LabelSelectorRequirement{{Key: "tier", Operator: "Bogus"}},
+						},
+						"Invalid label selector: \"Bogus\" is not a valid label selector operator"),
+					field.Invalid(field.NewPath("spec", "resource", "fieldSelector"), "metadata.name",
+						"Invalid field selector: invalid selector: 'metadata.name'; can't understand 'metadata.name'"),
+				}.ToAggregate(),
+			}),
+	)
+
+	DescribeTable("Update validation failures",
+		func(oldClass, newClass ServiceClass, expected validationResult) {
+			w, err := validator.ValidateUpdate(context.Background(), &oldClass, &newClass)
+			obtained := validationResult{
+				warnings: w,
+				err:      err,
+			}
+			Expect(obtained).To(Equal(expected))
+		},
+		Entry("Resource Kind is immutable",
 			newServiceClass("spam", "eggs",
 				ServiceClassSpec{
 					Resource: ServiceClassResource{
@@ -181,8 +323,8 @@ var _ = Describe("Webhook tests", func() {
 			newServiceClass("spam", "eggs",
 				ServiceClassSpec{
 					Resource: ServiceClassResource{
-						APIVersion: "foo.bam",
-						Kind:       "baz",
+						APIVersion: "foo.bar/v1",
+						Kind:       "bam",
 						ServiceEndpointDefinitionMappings: ServiceEndpointDefinitionMappings{
 							ResourceFields: []ServiceClassResourceFieldMapping{
 								{
@@ -195,7 +337,7 @@ var _ = Describe("Webhook tests", func() {
 				}),
 			validationResult{
 				err: field.ErrorList{
-					field.Invalid(field.NewPath("spec", "resource", "apiVersion"), "foo.bam", "APIVersion is immutable"),
+					field.Invalid(field.NewPath("spec", "resource", "kind"), "bam", "Kind is immutable"),
 				}.ToAggregate(),
 			}),
 		Entry("Resource APIVersion is immutable",
@@ -217,13 +359,13 @@ var _ = Describe("Webhook tests", func() {
 			newServiceClass("spam", "eggs",
 				ServiceClassSpec{
 					Resource: ServiceClassResource{
-						APIVersion: "foo.bar/v1",
+						APIVersion: "foo.bam",
 						Kind:       "baz",
 						ServiceEndpointDefinitionMappings: ServiceEndpointDefinitionMappings{
 							ResourceFields: []ServiceClassResourceFieldMapping{
 								{
 									Name:     "x",
-									JsonPath: ".metadata",
+									JsonPath: ".spec",
 								},
 							},
 						},
@@ -231,21 +373,48 @@ var _ = Describe("Webhook tests", func() {
 				}),
 			validationResult{
 				err: field.ErrorList{
-					field.Invalid(
-						field.NewPath("spec", "resource", "serviceEndpointDefinitionMapping"),
-						ServiceEndpointDefinitionMappings{
-							ResourceFields: []ServiceClassResourceFieldMapping{
-								{
-									Name:     "x",
-									JsonPath: ".metadata",
-								},
-							},
-						},
-						"ServiceEndpointDefinitionMapping is immutable"),
+					field.Invalid(field.NewPath("spec", "resource", "apiVersion"), "foo.bam", "APIVersion is immutable"),
 				}.ToAggregate(),
 			}),
 	)
 
+	It("should allow mapping updates", func() {
+		oldClass := newServiceClass("spam", "eggs", ServiceClassSpec{
+			Resource: ServiceClassResource{
+				APIVersion: "foo.bar/v1",
+				Kind:       "baz",
+				ServiceEndpointDefinitionMappings: ServiceEndpointDefinitionMappings{
+					ResourceFields: []ServiceClassResourceFieldMapping{
+						{
+							Name:     "x",
+							JsonPath: ".spec",
+						},
+					},
+				},
+			},
+		})
+		newClass := newServiceClass("spam", "eggs", ServiceClassSpec{
+			Resource: ServiceClassResource{
+				APIVersion: "foo.bar/v1",
+				Kind:       "baz",
+				ServiceEndpointDefinitionMappings: ServiceEndpointDefinitionMappings{
+					ResourceFields: []ServiceClassResourceFieldMapping{
+						{
+							Name:     "x",
+							JsonPath: ".metadata",
+						},
+						{
+							Name:     "y",
+							JsonPath: ".status",
+						},
+					},
+				},
+			},
+		})
+
+		Expect(tr(validator.ValidateUpdate(context.Background(), &oldClass, &newClass))).NotTo(HaveOccurred())
+	})
+
 	It("should reject non-ServiceClass old objects", func() {
 		oldObject := unstructured.Unstructured{}
 		newObject := newServiceClass("spam", "eggs", ServiceClassSpec{
@@ -288,6 +457,38 @@ var _ = Describe("Webhook tests", func() {
 		Expect(tr(validator.ValidateDelete(context.Background(), &object))).NotTo(HaveOccurred())
 	})
 
+	It("should reject invalid CEL expressions", func() {
+		expr := "self.spec.x +"
+		serviceClass := newServiceClass("spam", "eggs",
+			ServiceClassSpec{
+				Resource: ServiceClassResource{
+					APIVersion: "foo.bar/v1",
+					Kind:       "baz",
+					ServiceEndpointDefinitionMappings: ServiceEndpointDefinitionMappings{
+						ResourceFields: []ServiceClassResourceFieldMapping{
+							{
+								Name:       "x",
+								Expression: expr,
+							},
+						},
+						SecretRefFields: []ServiceClassSecretRefFieldMapping{
+							{
+								Name:       "y",
+								SecretName: FieldMapping{Expression: &expr},
+								SecretKey:  FieldMapping{Constant: &expr},
+							},
+						},
+					},
+				},
+			},
+		)
+
+		_, err := validator.ValidateCreate(context.Background(), &serviceClass)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.resource.serviceEndpointDefinitionMapping[0].expression: Invalid value: \"self.spec.x +\": Invalid CEL expression"))
+		Expect(err.Error()).To(ContainSubstring("spec.resource.serviceEndpointDefinitionMappings.secretRefFields[0].secretName.expression: Invalid value: \"self.spec.x +\": Invalid CEL expression"))
+	})
+
 	It("should disallow service classes with the same resource type", func() {
 		schemeBuilder, err := SchemeBuilder.Build()
 		Expect(err).NotTo(HaveOccurred())
@@ -342,4 +543,54 @@ var _ = Describe("Webhook tests", func() {
 			Expect(obtained).To(Equal(expected))
 		}
 	})
+
+	It("should disallow service classes with the same additional resource type", func() {
+		schemeBuilder, err := SchemeBuilder.Build()
+		Expect(err).NotTo(HaveOccurred())
+
+		class := newServiceClass("spam", "eggs", ServiceClassSpec{
+			Resource: ServiceClassResource{
+				APIVersion: "foo.bar/v1",
+				Kind:       "baz",
+			},
+			AdditionalResources: []ServiceClassResource{
+				{
+					APIVersion: "foo.bar/v1",
+					Kind:       "bam",
+				},
+			},
+		})
+
+		validator = serviceClassValidator{
+			client: fake.NewClientBuilder().
+				WithScheme(schemeBuilder).
+				WithLists(&ServiceClassList{}).
+				WithRuntimeObjects(class.DeepCopy()).
+				Build(),
+		}
+
+		other := newServiceClass("beans", "eggs", ServiceClassSpec{
+			Resource: ServiceClassResource{
+				APIVersion: "foo.bar/v1",
+				Kind:       "qux",
+			},
+			AdditionalResources: []ServiceClassResource{
+				{
+					APIVersion: "foo.bar/v1",
+					Kind:       "bam",
+				},
+			},
+		})
+
+		w, err := validator.ValidateCreate(context.Background(), &other)
+		obtained := validationResult{warnings: w, err: err}
+		expected := validationResult{
+			warnings: nil,
+			err: field.ErrorList{
+				field.Forbidden(field.NewPath("spec", "additionalResources").Index(0), "Service Class spam already manages services of type bam.foo.bar/v1"),
+			}.ToAggregate(),
+		}
+
+		Expect(obtained).To(Equal(expected))
+	})
 })
@@ -28,6 +28,37 @@ type ServiceClassIdentityItem struct {
 	Value string `json:"value"`
 }
 
+type ServiceClassIdentityOperator string
+
+const (
+	ServiceClassIdentityOperatorIn           ServiceClassIdentityOperator = "In"
+	ServiceClassIdentityOperatorNotIn        ServiceClassIdentityOperator = "NotIn"
+	ServiceClassIdentityOperatorExists       ServiceClassIdentityOperator = "Exists"
+	ServiceClassIdentityOperatorDoesNotExist ServiceClassIdentityOperator = "DoesNotExist"
+	ServiceClassIdentityOperatorVersionRange ServiceClassIdentityOperator = "VersionRange"
+)
+
+// ServiceClassIdentityRequirement defines a set-based requirement on the
+// attributes that identify a service class.
+// +kubebuilder:validation:XValidation:rule="(self.operator == 'In' || self.operator == 'NotIn' || self.operator == 'VersionRange') == (has(self.values) && size(self.values) > 0)",message="`values` must be set for `In`, `NotIn`, and `VersionRange` operators only"
+// +kubebuilder:validation:XValidation:rule="self.operator != 'VersionRange' || size(self.values) == 1",message="`VersionRange` operator requires exactly one value"
+type ServiceClassIdentityRequirement struct {
+	// Name of the service class identity attribute the requirement applies to.
+	Name string `json:"name"`
+
+	// Operator represents the relationship between the attribute and the values.
+	// VersionRange matches attributes whose value is a version satisfying
+	// the range, like ">= 13.2 < 16".
+	//+kubebuilder:validation:Enum=In;NotIn;Exists;DoesNotExist;VersionRange
+	Operator ServiceClassIdentityOperator `json:"operator"`
+
+	// Values is the set of values the operator applies to.
+	// It must be set for In and NotIn operators, must contain exactly one
+	// version range for VersionRange operator, and must be empty otherwise.
+	// +optional
+	Values []string `json:"values,omitempty"`
+}
+
 // Application resource to inject the binding info.
 // It could be any process running within a container.
 // +kubebuilder:validation:XValidation:rule="!(has(self.name) && has(self.selector))",message="`name` and `selector` can not be used at the same time"
@@ -221,6 +221,11 @@ func (in *FieldMapping) DeepCopyInto(out *FieldMapping) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Expression != nil {
+		in, out := &in.Expression, &out.Expression
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldMapping.
@@ -269,13 +274,28 @@ func (in *HealthCheckContainer) DeepCopy() *HealthCheckContainer {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OptionalMapping) DeepCopyInto(out *OptionalMapping) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OptionalMapping.
+func (in *OptionalMapping) DeepCopy() *OptionalMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(OptionalMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RegisteredService) DeepCopyInto(out *RegisteredService) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegisteredService.
@@ -348,6 +368,21 @@ func (in *RegisteredServiceList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegisteredServiceSharing) DeepCopyInto(out *RegisteredServiceSharing) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegisteredServiceSharing.
+func (in *RegisteredServiceSharing) DeepCopy() *RegisteredServiceSharing {
+	if in == nil {
+		return nil
+	}
+	out := new(RegisteredServiceSharing)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RegisteredServiceSpec) DeepCopyInto(out *RegisteredServiceSpec) {
 	*out = *in
@@ -361,6 +396,11 @@ func (in *RegisteredServiceSpec) DeepCopyInto(out *RegisteredServiceSpec) {
 		*out = new(HealthCheck)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Sharing != nil {
+		in, out := &in.Sharing, &out.Sharing
+		*out = new(RegisteredServiceSharing)
+		**out = **in
+	}
 	if in.ServiceClassIdentity != nil {
 		in, out := &in.ServiceClassIdentity, &out.ServiceClassIdentity
 		*out = make([]ServiceClassIdentityItem, len(*in))
@@ -388,6 +428,11 @@ func (in *RegisteredServiceSpec) DeepCopy() *RegisteredServiceSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RegisteredServiceStatus) DeepCopyInto(out *RegisteredServiceStatus) {
 	*out = *in
+	if in.Claims != nil {
+		in, out := &in.Claims, &out.Claims
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegisteredServiceStatus.
@@ -682,6 +727,31 @@ func (in *ServiceClaimApplicationClusterContext) DeepCopy() *ServiceClaimApplica
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceClaimDryRunResult) DeepCopyInto(out *ServiceClaimDryRunResult) {
+	*out = *in
+	if in.RegisteredService != nil {
+		in, out := &in.RegisteredService, &out.RegisteredService
+		*out = new(corev1.ObjectReference)
+		**out = **in
+	}
+	if in.SecretKeys != nil {
+		in, out := &in.SecretKeys, &out.SecretKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceClaimDryRunResult.
+func (in *ServiceClaimDryRunResult) DeepCopy() *ServiceClaimDryRunResult {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceClaimDryRunResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceClaimList) DeepCopyInto(out *ServiceClaimList) {
 	*out = *in
@@ -722,6 +792,13 @@ func (in *ServiceClaimSpec) DeepCopyInto(out *ServiceClaimSpec) {
 		*out = make([]ServiceClassIdentityItem, len(*in))
 		copy(*out, *in)
 	}
+	if in.ServiceClassIdentityExpressions != nil {
+		in, out := &in.ServiceClassIdentityExpressions, &out.ServiceClassIdentityExpressions
+		*out = make([]ServiceClassIdentityRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.ServiceEndpointDefinitionKeys != nil {
 		in, out := &in.ServiceEndpointDefinitionKeys, &out.ServiceEndpointDefinitionKeys
 		*out = make([]string, len(*in))
@@ -765,6 +842,11 @@ func (in *ServiceClaimStatus) DeepCopyInto(out *ServiceClaimStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DryRun != nil {
+		in, out := &in.DryRun, &out.DryRun
+		*out = new(ServiceClaimDryRunResult)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceClaimStatus.
@@ -824,6 +906,24 @@ func (in *ServiceClass) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceClassConfigMapRefFieldMapping) DeepCopyInto(out *ServiceClassConfigMapRefFieldMapping) {
+	*out = *in
+	in.ConfigMapName.DeepCopyInto(&out.ConfigMapName)
+	in.ConfigMapKey.DeepCopyInto(&out.ConfigMapKey)
+	out.OptionalMapping = in.OptionalMapping
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceClassConfigMapRefFieldMapping.
+func (in *ServiceClassConfigMapRefFieldMapping) DeepCopy() *ServiceClassConfigMapRefFieldMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceClassConfigMapRefFieldMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceClassIdentityItem) DeepCopyInto(out *ServiceClassIdentityItem) {
 	*out = *in
@@ -839,6 +939,26 @@ func (in *ServiceClassIdentityItem) DeepCopy() *ServiceClassIdentityItem {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceClassIdentityRequirement) DeepCopyInto(out *ServiceClassIdentityRequirement) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceClassIdentityRequirement.
+func (in *ServiceClassIdentityRequirement) DeepCopy() *ServiceClassIdentityRequirement {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceClassIdentityRequirement)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceClassList) DeepCopyInto(out *ServiceClassList) {
 	*out = *in
@@ -871,10 +991,51 @@ func (in *ServiceClassList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceClassProviderRefFieldMapping) DeepCopyInto(out *ServiceClassProviderRefFieldMapping) {
+	*out = *in
+	in.Key.DeepCopyInto(&out.Key)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceClassProviderRefFieldMapping.
+func (in *ServiceClassProviderRefFieldMapping) DeepCopy() *ServiceClassProviderRefFieldMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceClassProviderRefFieldMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceClassReadinessGate) DeepCopyInto(out *ServiceClassReadinessGate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceClassReadinessGate.
+func (in *ServiceClassReadinessGate) DeepCopy() *ServiceClassReadinessGate {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceClassReadinessGate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceClassResource) DeepCopyInto(out *ServiceClassResource) {
 	*out = *in
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	in.ServiceEndpointDefinitionMappings.DeepCopyInto(&out.ServiceEndpointDefinitionMappings)
+	if in.ReadinessGate != nil {
+		in, out := &in.ReadinessGate, &out.ReadinessGate
+		*out = new(ServiceClassReadinessGate)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceClassResource.
@@ -890,6 +1051,7 @@ func (in *ServiceClassResource) DeepCopy() *ServiceClassResource {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceClassResourceFieldMapping) DeepCopyInto(out *ServiceClassResourceFieldMapping) {
 	*out = *in
+	out.OptionalMapping = in.OptionalMapping
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceClassResourceFieldMapping.
@@ -907,6 +1069,12 @@ func (in *ServiceClassSecretRefFieldMapping) DeepCopyInto(out *ServiceClassSecre
 	*out = *in
 	in.SecretName.DeepCopyInto(&out.SecretName)
 	in.SecretKey.DeepCopyInto(&out.SecretKey)
+	if in.SecretNamespace != nil {
+		in, out := &in.SecretNamespace, &out.SecretNamespace
+		*out = new(FieldMapping)
+		(*in).DeepCopyInto(*out)
+	}
+	out.OptionalMapping = in.OptionalMapping
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceClassSecretRefFieldMapping.
@@ -933,6 +1101,13 @@ func (in *ServiceClassSpec) DeepCopyInto(out *ServiceClassSpec) {
 		(*in).DeepCopyInto(*out)
 	}
 	in.Resource.DeepCopyInto(&out.Resource)
+	if in.AdditionalResources != nil {
+		in, out := &in.AdditionalResources, &out.AdditionalResources
+		*out = make([]ServiceClassResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.ServiceClassIdentity != nil {
 		in, out := &in.ServiceClassIdentity, &out.ServiceClassIdentity
 		*out = make([]ServiceClassIdentityItem, len(*in))
@@ -972,6 +1147,21 @@ func (in *ServiceClassStatus) DeepCopy() *ServiceClassStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceClassTemplateFieldMapping) DeepCopyInto(out *ServiceClassTemplateFieldMapping) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceClassTemplateFieldMapping.
+func (in *ServiceClassTemplateFieldMapping) DeepCopy() *ServiceClassTemplateFieldMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceClassTemplateFieldMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceEndpointDefinitionItem) DeepCopyInto(out *ServiceEndpointDefinitionItem) {
 	*out = *in
@@ -980,6 +1170,11 @@ func (in *ServiceEndpointDefinitionItem) DeepCopyInto(out *ServiceEndpointDefini
 		*out = new(ServiceEndpointDefinitionSecretRef)
 		**out = **in
 	}
+	if in.ValueFromProvider != nil {
+		in, out := &in.ValueFromProvider, &out.ValueFromProvider
+		*out = new(ServiceEndpointDefinitionProviderRef)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceEndpointDefinitionItem.
@@ -1007,6 +1202,25 @@ func (in *ServiceEndpointDefinitionMappings) DeepCopyInto(out *ServiceEndpointDe
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ConfigMapRefFields != nil {
+		in, out := &in.ConfigMapRefFields, &out.ConfigMapRefFields
+		*out = make([]ServiceClassConfigMapRefFieldMapping, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ProviderRefFields != nil {
+		in, out := &in.ProviderRefFields, &out.ProviderRefFields
+		*out = make([]ServiceClassProviderRefFieldMapping, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TemplateFields != nil {
+		in, out := &in.TemplateFields, &out.TemplateFields
+		*out = make([]ServiceClassTemplateFieldMapping, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceEndpointDefinitionMappings.
@@ -1019,6 +1233,21 @@ func (in *ServiceEndpointDefinitionMappings) DeepCopy() *ServiceEndpointDefiniti
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceEndpointDefinitionProviderRef) DeepCopyInto(out *ServiceEndpointDefinitionProviderRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceEndpointDefinitionProviderRef.
+func (in *ServiceEndpointDefinitionProviderRef) DeepCopy() *ServiceEndpointDefinitionProviderRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceEndpointDefinitionProviderRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceEndpointDefinitionSecretRef) DeepCopyInto(out *ServiceEndpointDefinitionSecretRef) {
 	*out = *in
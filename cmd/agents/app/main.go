@@ -120,6 +120,10 @@ func main() {
 			os.Exit(1)
 		}
 	}
+	if err = (&primazaiov1alpha1.ServiceClaim{}).SetupAgentWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "ServiceClaim")
+		os.Exit(1)
+	}
 	if err = (&controllers.ServiceCatalogReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
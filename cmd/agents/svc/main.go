@@ -118,6 +118,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	annotatedServiceController := svc.NewAnnotatedServiceReconciler(mgr, *s)
+	if err = annotatedServiceController.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Annotated Service")
+		os.Exit(1)
+	}
+
 	agentServiceController := svc.NewAgentServiceReconciler(mgr)
 	if err = agentServiceController.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Agent Service")
@@ -0,0 +1,122 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
+	// to ensure that exec-entrypoint and run can make use of them.
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	primazaiov1alpha1 "github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/primaza/inventory"
+)
+
+var (
+	scheme   = runtime.NewScheme()
+	setupLog = ctrl.Log.WithName("inventory")
+)
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+
+	utilruntime.Must(primazaiov1alpha1.AddToScheme(scheme))
+}
+
+// inventory imports the services listed in an inventory file as
+// RegisteredServices in Primaza's Control Plane namespace
+func main() {
+	var file string
+	var namespace string
+	var source string
+	var dryRun bool
+	flag.StringVar(&file, "file", "", "The inventory file to import.")
+	flag.StringVar(&namespace, "namespace", "primaza-system", "The namespace of Primaza's Control Plane.")
+	flag.StringVar(&source, "source", "",
+		"The name identifying the inventory. "+
+			"Services previously imported from the same source and no more listed are deleted. "+
+			"If not set, the name of the file without extension is used. "+
+			"Sources starting with '"+inventory.ConfigMapSourcePrefix+"' are reserved to inventory ConfigMaps.")
+	flag.BoolVar(&dryRun, "dry-run", false, "Validate the inventory and the changes, without applying them.")
+	opts := zap.Options{
+		Development: true,
+	}
+	opts.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+
+	if err := run(file, namespace, source, dryRun); err != nil {
+		setupLog.Error(err, "unable to import inventory", "file", file)
+		os.Exit(1)
+	}
+}
+
+func run(file, namespace, source string, dryRun bool) error {
+	if file == "" {
+		return fmt.Errorf("inventory file is required")
+	}
+	if source == "" {
+		source = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	}
+	if strings.HasPrefix(source, inventory.ConfigMapSourcePrefix) {
+		return fmt.Errorf("inventory source '%s' is reserved to inventory ConfigMaps", source)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	inv, err := inventory.Parse(data)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		return err
+	}
+	cli, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		cli = client.NewDryRunClient(cli)
+	}
+
+	importer := inventory.Importer{
+		Client:    cli,
+		Namespace: namespace,
+		Source:    source,
+	}
+	ctx := log.IntoContext(context.Background(), setupLog)
+	return importer.Sync(ctx, *inv)
+}
@@ -42,6 +42,8 @@ import (
 
 	primazaiov1alpha1 "github.com/primaza/primaza/api/v1alpha1"
 	"github.com/primaza/primaza/controllers"
+	"github.com/primaza/primaza/pkg/primaza/clustercontext"
+	"github.com/primaza/primaza/pkg/primaza/sed"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -74,11 +76,15 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var sedFileProviderDir string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&sedFileProviderDir, "sed-file-provider-dir", "",
+		"The directory the 'file' Service Endpoint Definition provider reads values from. "+
+			"If not set, the provider is not registered.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -118,6 +124,13 @@ func main() {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
+	if sedFileProviderDir != "" {
+		if err := sed.RegisterProvider(sed.FileProviderName, sed.NewFileProvider(sedFileProviderDir)); err != nil {
+			setupLog.Error(err, "unable to register provider", "provider", sed.FileProviderName)
+			os.Exit(1)
+		}
+	}
+
 	cerConfig := controllers.ClusterEnvironmentReconcilerConfig{
 		ControlPlaneNamespace:  cfg.WatchNamespace,
 		AppAgentImage:          cfg.AppImage,
@@ -132,12 +145,20 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "ClusterEnvironment")
 		os.Exit(1)
 	}
+	if err = (&primazaiov1alpha1.ClusterEnvironment{}).SetupWebhookWithManager(mgr, clustercontext.ExtractClusterRESTConfig); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "ClusterEnvironment")
+		os.Exit(1)
+	}
 
 	serviceClaimController := controllers.NewServiceClaimReconciler(mgr)
 	if err := serviceClaimController.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ServiceClaim")
 		os.Exit(1)
 	}
+	if err = (&primazaiov1alpha1.ServiceClaim{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "ServiceClaim")
+		os.Exit(1)
+	}
 	if err = (&controllers.ServiceClassReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
@@ -156,6 +177,10 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "RegisteredService")
 		os.Exit(1)
 	}
+	if err = (&primazaiov1alpha1.RegisteredService{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "RegisteredService")
+		os.Exit(1)
+	}
 
 	if err = (&controllers.ServiceCatalogReconciler{
 		Client: mgr.GetClient(),
@@ -164,6 +189,14 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "ServiceCatalog")
 		os.Exit(1)
 	}
+
+	if err = (&controllers.InventoryReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "Inventory")
+		os.Exit(1)
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
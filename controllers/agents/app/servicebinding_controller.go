@@ -18,12 +18,16 @@ package controllers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"path"
+	"slices"
 	"strings"
 
 	primazaiov1alpha1 "github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/primaza/constants"
 	v1 "k8s.io/api/core/v1"
 	meta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -313,6 +317,11 @@ func (r *ServiceBindingReconciler) prepareContainerWithMounts(
 		l.Info("application object after setting the updated containers", "Application", application)
 	}
 
+	l.Info("updating the secret checksum in the application's pod template")
+	if err := setSecretChecksumAnnotation(application, *sb, psSecret); err != nil {
+		return err
+	}
+
 	l.Info("updating the application with updated volumes and volumeMounts")
 	if err := r.Update(ctx, &application); err != nil {
 		l.Error(err, "unable to update the application", "application", application)
@@ -682,6 +691,11 @@ func (r *ServiceBindingReconciler) removeVolumeMountAndEnvironment(ctx context.C
 		}
 	}
 
+	l.Info("removing the secret checksum from the application's pod template")
+	if err := setSecretChecksumAnnotation(application, sb, nil); err != nil {
+		return err
+	}
+
 	l.Info("updating the application with updated volumes and volumeMounts")
 	if err := r.Update(ctx, &application); err != nil {
 		l.Error(err, "unable to update the application", "application", application)
@@ -709,6 +723,50 @@ func (r *ServiceBindingReconciler) unbindApplications(ctx context.Context,
 	return nil
 }
 
+// setSecretChecksumAnnotation stamps the checksum of the
+// ServiceEndpointDefinitionSecret into the application's pod template when the
+// ServiceBinding's RestartPolicy is OnSecretChange, so that the workload's pods
+// are rolled out when the secret changes.  Otherwise, or when psSecret is nil,
+// the annotation is removed.
+func setSecretChecksumAnnotation(application unstructured.Unstructured, sb primazaiov1alpha1.ServiceBinding, psSecret *v1.Secret) error {
+	annotationsPath := []string{"spec", "template", "metadata", "annotations"}
+	annotations, _, err := unstructured.NestedStringMap(application.Object, annotationsPath...)
+	if err != nil {
+		return err
+	}
+
+	key := constants.SecretChecksumAnnotationPrefix + sb.Name
+	if psSecret != nil && sb.Spec.RestartPolicy == primazaiov1alpha1.ServiceBindingRestartPolicyOnSecretChange {
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[key] = secretDataChecksum(psSecret)
+	} else {
+		if _, found := annotations[key]; !found {
+			return nil
+		}
+		delete(annotations, key)
+	}
+
+	return unstructured.SetNestedStringMap(application.Object, annotations, annotationsPath...)
+}
+
+// secretDataChecksum computes a checksum of the secret's data that does not
+// depend on the keys' order
+func secretDataChecksum(secret *v1.Secret) string {
+	keys := make([]string, 0, len(secret.Data))
+	for k := range secret.Data {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%d:%s%d:%s", len(k), k, len(secret.Data[k]), secret.Data[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 func verifyApplicationSatisfiesServiceBindingSpec(obj *unstructured.Unstructured, sb primazaiov1alpha1.ServiceBinding) bool {
 	switch {
 	case sb.Spec.Application.Name == obj.GetName():
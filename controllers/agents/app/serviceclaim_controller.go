@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"strings"
 
@@ -26,12 +27,16 @@ import (
 	meta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	primazaiov1alpha1 "github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/primaza/claim"
 	"github.com/primaza/primaza/pkg/primaza/constants"
 	"github.com/primaza/primaza/pkg/primaza/workercluster"
 )
@@ -142,6 +147,11 @@ func (r *ServiceClaimReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, nil
 	}
 
+	if err := r.checkServiceCatalog(ctx, &sclaim); err != nil {
+		l.Error(err, "unable to check the ServiceCatalog for matching services")
+		return ctrl.Result{}, err
+	}
+
 	sclaimCopy := r.createServiceClaimCopy(sclaim, deployment, remote_namespace)
 	spec := sclaimCopy.Spec
 
@@ -177,6 +187,44 @@ func (r *ServiceClaimReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	return ctrl.Result{}, nil
 }
 
+// checkServiceCatalog looks for services matching the ServiceClaim in the
+// ServiceCatalogs available in the ServiceClaim's namespace, and reports the
+// outcome in the ServiceClaim's Cataloged condition.  The ServiceClaim is
+// forwarded to Primaza's Control Plane anyway, as a matching service may be
+// registered later on.
+func (r *ServiceClaimReconciler) checkServiceCatalog(ctx context.Context, sclaim *primazaiov1alpha1.ServiceClaim) error {
+	var scl primazaiov1alpha1.ServiceCatalogList
+	if err := r.List(ctx, &scl, client.InNamespace(sclaim.Namespace)); err != nil {
+		return err
+	}
+
+	c := metav1.Condition{
+		LastTransitionTime: metav1.Now(),
+		Type:               string(primazaiov1alpha1.ServiceClaimConditionCataloged),
+		Status:             metav1.ConditionFalse,
+		Reason:             constants.NoMatchingServiceFoundReason,
+		Message:            "no service matching the claim found in the service catalogs, the claim will be pending until one is registered",
+	}
+	for _, sc := range scl.Items {
+		if scs := claim.FilterServiceCatalog(sclaim.Spec, sc); len(scs) > 0 {
+			c.Status = metav1.ConditionTrue
+			c.Reason = constants.MatchingServicesFoundReason
+			c.Message = fmt.Sprintf("%d services matching the claim found in service catalog %s", len(scs), sc.Name)
+			break
+		}
+	}
+
+	if old := meta.FindStatusCondition(sclaim.Status.Conditions, c.Type); old != nil &&
+		old.Status == c.Status && old.Reason == c.Reason && old.Message == c.Message {
+		return nil
+	}
+	meta.SetStatusCondition(&sclaim.Status.Conditions, c)
+	if sclaim.Status.State == "" {
+		sclaim.Status.State = primazaiov1alpha1.ServiceClaimStatePending
+	}
+	return r.Status().Update(ctx, sclaim)
+}
+
 func (r *ServiceClaimReconciler) createServiceClaimCopy(sclaim primazaiov1alpha1.ServiceClaim, deployment appsv1.Deployment, remote_namespace string) *primazaiov1alpha1.ServiceClaim {
 	sclaimCopy := sclaim.DeepCopy()
 	sclaimCopy.Spec.Target = &primazaiov1alpha1.ServiceClaimTarget{
@@ -200,7 +248,30 @@ func (r *ServiceClaimReconciler) deleteExternalResources(ctx context.Context, sc
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ServiceClaimReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// ServiceCatalogs are watched so that the Cataloged condition is kept
+	// up to date
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&primazaiov1alpha1.ServiceClaim{}).
+		Watches(&primazaiov1alpha1.ServiceCatalog{}, handler.EnqueueRequestsFromMapFunc(r.reconcileOnServiceCatalogUpdate)).
 		Complete(r)
 }
+
+// reconcileOnServiceCatalogUpdate maps a ServiceCatalog to the ServiceClaims
+// in its namespace
+func (r *ServiceClaimReconciler) reconcileOnServiceCatalogUpdate(ctx context.Context, a client.Object) []reconcile.Request {
+	l := log.FromContext(ctx).WithValues("service catalog", a.GetName())
+
+	var scl primazaiov1alpha1.ServiceClaimList
+	if err := r.List(ctx, &scl, client.InNamespace(a.GetNamespace())); err != nil {
+		l.Error(err, "unable to list the ServiceClaims to reconcile for service catalog update")
+		return []reconcile.Request{}
+	}
+
+	requests := make([]reconcile.Request, 0, len(scl.Items))
+	for _, sc := range scl.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: sc.Namespace, Name: sc.Name},
+		})
+	}
+	return requests
+}
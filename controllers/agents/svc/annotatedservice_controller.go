@@ -0,0 +1,177 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package svc
+
+import (
+	"context"
+	"errors"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/primaza/annotatedservice"
+	"github.com/primaza/primaza/pkg/primaza/constants"
+)
+
+// AnnotatedServiceReconciler registers the Kubernetes Services annotated
+// with a ServiceClassIdentity, without the need of a ServiceClass
+type AnnotatedServiceReconciler struct {
+	client.Client
+	recorder                record.EventRecorder
+	synchronizationStrategy v1alpha1.SynchronizationStrategy
+}
+
+func NewAnnotatedServiceReconciler(mgr ctrl.Manager, strategy v1alpha1.SynchronizationStrategy) *AnnotatedServiceReconciler {
+	return &AnnotatedServiceReconciler{
+		Client:                  mgr.GetClient(),
+		recorder:                mgr.GetEventRecorderFor("annotatedservice-controller"),
+		synchronizationStrategy: strategy,
+	}
+}
+
+func (r *AnnotatedServiceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	l := log.FromContext(ctx)
+	l.Info("Reconciling annotated Service")
+
+	config, target_namespace, err := getTargetClient(ctx, r.synchronizationStrategy, req.Namespace)
+	if err != nil {
+		l.Error(err, "Failed to get the configuration of the target cluster")
+		return ctrl.Result{}, err
+	}
+	target_client, err := client.New(config, client.Options{Scheme: r.Scheme()})
+	if err != nil {
+		l.Error(err, "Failed to create client for target cluster")
+		return ctrl.Result{}, err
+	}
+
+	var service v1.Service
+	if err := r.Get(ctx, req.NamespacedName, &service); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, r.deleteRegisteredService(ctx, target_client, req.NamespacedName, target_namespace)
+	}
+	if !service.DeletionTimestamp.IsZero() || !annotatedservice.IsAnnotated(&service) || service.GetAnnotations()[constants.IgnoreAnnotation] == "true" {
+		return ctrl.Result{}, r.deleteRegisteredService(ctx, target_client, req.NamespacedName, target_namespace)
+	}
+
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&service)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	obj := unstructured.Unstructured{Object: u}
+	obj.SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("Service"))
+
+	// the annotations need to be fixed, retrying is pointless.  The
+	// RegisteredService published from the previous annotations is deleted,
+	// as its values can not be trusted anymore.
+	serviceClass, err := annotatedservice.ServiceClass(&service, obj.GetAPIVersion(), obj.GetKind())
+	if err != nil {
+		l.Error(err, "Invalid Service annotations")
+		r.recorder.Event(&service, v1.EventTypeWarning, constants.InvalidAnnotationsReason, err.Error())
+		return ctrl.Result{}, r.deleteRegisteredService(ctx, target_client, req.NamespacedName, target_namespace)
+	}
+
+	mappings, err := ServiceEndpointDefinitionMapping(r.Client, obj, *serviceClass)
+	if err != nil {
+		l.Error(err, "Failed to build the Service Endpoint Definition mappings")
+		r.recorder.Event(&service, v1.EventTypeWarning, constants.MappingErrorReason, err.Error())
+		return ctrl.Result{}, r.deleteRegisteredService(ctx, target_client, req.NamespacedName, target_namespace)
+	}
+
+	rs, secret, err := PrepareRegisteredService(ctx, *serviceClass, mappings, obj, target_namespace)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, errors.Join(updateRegisteredService(ctx, target_client, rs, secret)...)
+}
+
+// deleteRegisteredService deletes the RegisteredService published for the
+// Service, if any.  RegisteredServices published for other resources with
+// the same name are left untouched.
+func (r *AnnotatedServiceReconciler) deleteRegisteredService(
+	ctx context.Context,
+	target_client client.Client,
+	service types.NamespacedName,
+	target_namespace string,
+) error {
+	key := types.NamespacedName{Name: service.Name, Namespace: target_namespace}
+	return deletePublishedRegisteredService(ctx, target_client, key, "v1", "Service", service.Namespace)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *AnnotatedServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Services losing the annotation are reconciled too, so that their
+	// RegisteredService is deleted
+	annotated := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return annotatedservice.IsAnnotated(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return annotatedservice.IsAnnotated(e.ObjectOld) || annotatedservice.IsAnnotated(e.ObjectNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return annotatedservice.IsAnnotated(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return annotatedservice.IsAnnotated(e.Object)
+		},
+	}
+
+	// Secrets are watched so that rotated values are pushed to Primaza's
+	// Control Plane
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("annotatedservice").
+		For(&v1.Service{}, builder.WithPredicates(annotated)).
+		Watches(&v1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.reconcileOnSecretUpdate)).
+		Complete(r)
+}
+
+// reconcileOnSecretUpdate maps a secret to the annotated Services in its
+// namespace reading values from it
+func (r *AnnotatedServiceReconciler) reconcileOnSecretUpdate(ctx context.Context, a client.Object) []reconcile.Request {
+	l := log.FromContext(ctx).WithValues("secret", a.GetName())
+
+	var services v1.ServiceList
+	if err := r.List(ctx, &services, client.InNamespace(a.GetNamespace())); err != nil {
+		l.Error(err, "unable to list the Services to reconcile for secret update")
+		return []reconcile.Request{}
+	}
+
+	requests := []reconcile.Request{}
+	for _, s := range services.Items {
+		if annotatedservice.IsAnnotated(&s) && annotatedservice.ReferencesSecret(&s, a.GetName()) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: s.Name, Namespace: s.Namespace},
+			})
+		}
+	}
+	return requests
+}
@@ -21,6 +21,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"go.uber.org/atomic"
@@ -39,10 +41,15 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/authz"
+	"github.com/primaza/primaza/pkg/primaza/annotatedservice"
 	"github.com/primaza/primaza/pkg/primaza/constants"
+	"github.com/primaza/primaza/pkg/primaza/readiness"
 	"github.com/primaza/primaza/pkg/primaza/sed"
 	"github.com/primaza/primaza/pkg/primaza/workercluster"
 
@@ -55,6 +62,8 @@ const finalizer = "serviceclasses.primaza.io/finalizer"
 type ServiceClassReconciler struct {
 	client.Client
 	dynamic.Interface
+	config                  *rest.Config
+	apiReader               client.Reader
 	informers               map[string]informer
 	synchronizationStrategy primazaiov1alpha1.SynchronizationStrategy
 }
@@ -63,6 +72,8 @@ type informer struct {
 	informer   cache.SharedIndexInformer
 	ctx        context.Context
 	cancelFunc context.CancelFunc
+	// generation of the ServiceClass the informer has been started for
+	generation int64
 }
 
 func (i *informer) run() {
@@ -73,6 +84,8 @@ func NewServiceClassReconciler(mgr ctrl.Manager, strategy primazaiov1alpha1.Sync
 	return &ServiceClassReconciler{
 		Client:                  mgr.GetClient(),
 		Interface:               dynamic.NewForConfigOrDie(mgr.GetConfig()),
+		config:                  mgr.GetConfig(),
+		apiReader:               mgr.GetAPIReader(),
 		informers:               make(map[string]informer, 0),
 		synchronizationStrategy: strategy,
 	}
@@ -154,10 +167,7 @@ func (r *ServiceClassReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}
 	} else if controllerutil.ContainsFinalizer(&serviceClass, finalizer) {
 		// need to stop the informers if the service class is deleted
-		if i, ok := r.informers[serviceClass.Name]; ok {
-			i.cancelFunc()
-			delete(r.informers, serviceClass.Name)
-		}
+		r.stopInformers(serviceClass.Name, nil)
 
 		// act on the registered service
 		err = r.HandleRegisteredServices(ctx, &serviceClass, *services, deleteRegisteredService)
@@ -187,12 +197,11 @@ func (r *ServiceClassReconciler) Reconcile(ctx context.Context, req ctrl.Request
 }
 
 func updateRegisteredService(ctx context.Context, target_client client.Client, rs v1alpha1.RegisteredService, secret *v1.Secret) []error {
-	spec := rs.Spec
 	reconcileLog := log.FromContext(ctx).WithValues("namespace", rs.Namespace, "name", rs.Name)
-	op, err := controllerutil.CreateOrUpdate(ctx, target_client, &rs, func() error {
-		rs.Spec = spec
-		return nil
-	})
+	if isNotReady(rs) {
+		return []error{markRegisteredServiceNotReady(ctx, target_client, rs)}
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, target_client, &rs, mutateRegisteredService(&rs))
 	if err != nil {
 		reconcileLog.Error(err, "Failed to create registered service", "service", rs.Name, "namespace", rs.Namespace)
 	} else {
@@ -200,23 +209,123 @@ func updateRegisteredService(ctx context.Context, target_client client.Client, r
 	}
 	errs := []error{err}
 	if secret != nil {
-		data := secret.StringData
-		_, err := controllerutil.CreateOrUpdate(ctx, target_client, secret, func() error {
-			secret.StringData = data
-			return controllerutil.SetOwnerReference(&rs, secret, target_client.Scheme())
-		})
-		errs = append(errs, err)
+		errs = append(errs, writeDescriptorSecret(ctx, target_client, rs, secret))
 	}
 	return errs
 }
 
-func deleteRegisteredService(ctx context.Context, target_client client.Client, rs v1alpha1.RegisteredService, secret *v1.Secret) []error {
-	reconcileLog := log.FromContext(ctx).WithValues("namespace", rs.Namespace, "name", rs.Name)
-	if err := target_client.Delete(ctx, &rs); err != nil {
+// writeDescriptorSecret creates or updates the secret holding the
+// RegisteredService's secret values.  Keys that are no more defined by the
+// ServiceClass's mappings are removed.
+func writeDescriptorSecret(ctx context.Context, target_client client.Client, rs v1alpha1.RegisteredService, secret *v1.Secret) error {
+	data := secret.StringData
+	_, err := controllerutil.CreateOrUpdate(ctx, target_client, secret, func() error {
+		secret.Data = nil
+		secret.StringData = data
+		return controllerutil.SetOwnerReference(&rs, secret, target_client.Scheme())
+	})
+	return err
+}
+
+// mutateRegisteredService returns a function restoring the spec and the
+// missing keys and not ready annotations of the given RegisteredService.
+// RegisteredServices with the same name published for another resource are
+// not overwritten.
+func mutateRegisteredService(rs *v1alpha1.RegisteredService) controllerutil.MutateFn {
+	spec := rs.Spec
+	apiVersion, kind, namespace := serviceOf(*rs)
+	annotations := map[string]*string{
+		constants.MissingKeysAnnotation:     nil,
+		constants.ServiceNotReadyAnnotation: nil,
+	}
+	for k := range annotations {
+		if v, ok := rs.GetAnnotations()[k]; ok {
+			annotations[k] = &v
+		}
+	}
+	return func() error {
+		if !isPublishedFor(*rs, apiVersion, kind, namespace) {
+			return fmt.Errorf("registered service %s is already published for a resource of another type or namespace", rs.Name)
+		}
+		rs.Spec = spec
+		for k, v := range annotations {
+			if v != nil {
+				metav1.SetMetaDataAnnotation(&rs.ObjectMeta, k, *v)
+			} else {
+				delete(rs.Annotations, k)
+			}
+		}
+		return nil
+	}
+}
+
+// markRegisteredServiceNotReady annotates the published RegisteredService
+// as not ready, leaving its specification untouched.  RegisteredServices
+// that have not been published yet are not created.
+func markRegisteredServiceNotReady(ctx context.Context, target_client client.Client, rs v1alpha1.RegisteredService) error {
+	l := log.FromContext(ctx).WithValues("namespace", rs.Namespace, "name", rs.Name)
+	reason := rs.GetAnnotations()[constants.ServiceNotReadyAnnotation]
+
+	var current v1alpha1.RegisteredService
+	if err := target_client.Get(ctx, client.ObjectKeyFromObject(&rs), &current); err != nil {
 		if apierrors.IsNotFound(err) {
-			// we tried to delete an object that doesn't exist, so
+			l.Info("Registered service not published: service is not ready", "reason", reason)
 			return nil
 		}
+		return err
+	}
+	if apiVersion, kind, namespace := serviceOf(rs); !isPublishedFor(current, apiVersion, kind, namespace) {
+		l.Info("Registered service not marked as not ready: it is published for another resource")
+		return nil
+	}
+
+	if current.GetAnnotations()[constants.ServiceNotReadyAnnotation] == reason {
+		return nil
+	}
+	base := current.DeepCopy()
+	metav1.SetMetaDataAnnotation(&current.ObjectMeta, constants.ServiceNotReadyAnnotation, reason)
+	if err := target_client.Patch(ctx, &current, client.MergeFrom(base)); err != nil {
+		l.Error(err, "Failed to mark registered service as not ready")
+		return err
+	}
+	l.Info("Marked registered service as not ready", "reason", reason)
+	return nil
+}
+
+// isIncomplete returns true if the RegisteredService has been registered
+// even if some of its keys could not be read
+func isIncomplete(rs v1alpha1.RegisteredService) bool {
+	_, ok := rs.GetAnnotations()[constants.MissingKeysAnnotation]
+	return ok
+}
+
+// isNotReady returns true if the resource the RegisteredService is
+// generated from does not satisfy the ServiceClass's readiness gate
+func isNotReady(rs v1alpha1.RegisteredService) bool {
+	_, ok := rs.GetAnnotations()[constants.ServiceNotReadyAnnotation]
+	return ok
+}
+
+// isPublishedFor returns true if the RegisteredService has been published
+// for a resource of the given type in the given namespace
+func isPublishedFor(rs v1alpha1.RegisteredService, apiVersion string, kind string, namespace string) bool {
+	a := rs.GetAnnotations()
+	return a[constants.ServiceAPIVersionAnnotation] == apiVersion &&
+		a[constants.ServiceKindAnnotation] == kind &&
+		a[constants.ServiceNamespaceAnnotation] == namespace
+}
+
+// serviceOf returns the type and the namespace of the resource the
+// RegisteredService has been prepared for
+func serviceOf(rs v1alpha1.RegisteredService) (string, string, string) {
+	a := rs.GetAnnotations()
+	return a[constants.ServiceAPIVersionAnnotation], a[constants.ServiceKindAnnotation], a[constants.ServiceNamespaceAnnotation]
+}
+
+func deleteRegisteredService(ctx context.Context, target_client client.Client, rs v1alpha1.RegisteredService, secret *v1.Secret) []error {
+	reconcileLog := log.FromContext(ctx).WithValues("namespace", rs.Namespace, "name", rs.Name)
+	apiVersion, kind, namespace := serviceOf(rs)
+	if err := deletePublishedRegisteredService(ctx, target_client, client.ObjectKeyFromObject(&rs), apiVersion, kind, namespace); err != nil {
 		reconcileLog.Error(err, "Failed to delete registered service", "namespace", rs.Namespace)
 		return []error{err}
 	}
@@ -226,32 +335,117 @@ func deleteRegisteredService(ctx context.Context, target_client client.Client, r
 	return nil
 }
 
-func (r *ServiceClassReconciler) GetResources(ctx context.Context, serviceClass *v1alpha1.ServiceClass) (*unstructured.UnstructuredList, error) {
-	typemeta := metav1.TypeMeta{
-		Kind:       serviceClass.Spec.Resource.Kind,
-		APIVersion: serviceClass.Spec.Resource.APIVersion,
+// deletePublishedRegisteredService deletes the RegisteredService with the
+// given name, if it has been published for a resource of the given type in
+// the given namespace.  RegisteredServices published for other resources with
+// the same name are left untouched.
+func deletePublishedRegisteredService(
+	ctx context.Context,
+	target_client client.Client,
+	key types.NamespacedName,
+	apiVersion string,
+	kind string,
+	namespace string,
+) error {
+	l := log.FromContext(ctx)
+
+	rs := v1alpha1.RegisteredService{}
+	if err := target_client.Get(ctx, key, &rs); err != nil {
+		return client.IgnoreNotFound(err)
 	}
-	gvk := typemeta.GroupVersionKind()
-	mapping, err := r.Client.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
-	if err != nil {
-		return nil, err
+	if !isPublishedFor(rs, apiVersion, kind, namespace) {
+		return nil
 	}
 
-	services, err := r.Interface.Resource(mapping.Resource).
-		Namespace(serviceClass.Namespace).
-		List(ctx, metav1.ListOptions{})
+	l.Info("Deleting registered service", "name", rs.Name, "namespace", rs.Namespace)
+	return client.IgnoreNotFound(target_client.Delete(ctx, &rs))
+}
 
-	if err != nil || services == nil {
-		return nil, err
+func (r *ServiceClassReconciler) GetResources(ctx context.Context, serviceClass *v1alpha1.ServiceClass) (*unstructured.UnstructuredList, error) {
+	services := &unstructured.UnstructuredList{}
+	for _, resource := range serviceClass.Spec.GetResources() {
+		typemeta := metav1.TypeMeta{
+			Kind:       resource.Kind,
+			APIVersion: resource.APIVersion,
+		}
+		gvk := typemeta.GroupVersionKind()
+		mapping, err := r.Client.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return nil, err
+		}
+
+		opts, err := resourceListOptions(resource)
+		if err != nil {
+			return nil, err
+		}
+
+		var ri dynamic.ResourceInterface = r.Interface.Resource(mapping.Resource)
+		if mapping.Scope.Name() != meta.RESTScopeNameRoot {
+			ri = r.Interface.Resource(mapping.Resource).Namespace(serviceClass.Namespace)
+		}
+		list, err := ri.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range list.Items {
+			if !isIgnored(s) && !isAnnotatedService(s) {
+				services.Items = append(services.Items, s)
+			}
+		}
 	}
 
 	return services, nil
 }
 
+// resourceListOptions returns the options selecting the resources of the
+// given type that the ServiceClass registers
+func resourceListOptions(resource v1alpha1.ServiceClassResource) (metav1.ListOptions, error) {
+	opts := metav1.ListOptions{FieldSelector: resource.FieldSelector}
+	if ls := resource.LabelSelector; ls != nil {
+		selector, err := metav1.LabelSelectorAsSelector(ls)
+		if err != nil {
+			return opts, err
+		}
+		opts.LabelSelector = selector.String()
+	}
+	return opts, nil
+}
+
+// lookupServiceClassResource returns the ServiceClass's resource type the
+// given resource belongs to
+func lookupServiceClassResource(serviceClass v1alpha1.ServiceClass, obj unstructured.Unstructured) (*v1alpha1.ServiceClassResource, error) {
+	resource := serviceClass.Spec.GetResource(obj.GetAPIVersion(), obj.GetKind())
+	if resource == nil {
+		return nil, fmt.Errorf("resource type %s.%s is not managed by ServiceClass %s", obj.GetKind(), obj.GetAPIVersion(), serviceClass.Name)
+	}
+	return resource, nil
+}
+
+// isIgnored returns true if the resource opted out of registration
+func isIgnored(obj unstructured.Unstructured) bool {
+	return obj.GetAnnotations()[constants.IgnoreAnnotation] == "true"
+}
+
+// isAnnotatedService returns true if the resource is a Kubernetes Service
+// registered through its own annotations by the AnnotatedServiceReconciler.
+// Such Services are skipped by ServiceClasses, so that they are not
+// published twice with different specifications.
+func isAnnotatedService(obj unstructured.Unstructured) bool {
+	return obj.GetAPIVersion() == "v1" && obj.GetKind() == "Service" && annotatedservice.IsAnnotated(&obj)
+}
+
 type HandleFunc func(context.Context, client.Client, v1alpha1.RegisteredService, *v1.Secret) []error
 
 func (r *ServiceClassReconciler) getTargetClient(ctx context.Context, namespace string) (*rest.Config, string, error) {
-	switch r.synchronizationStrategy {
+	return getTargetClient(ctx, r.synchronizationStrategy, namespace)
+}
+
+// getTargetClient returns the configuration and the namespace the
+// RegisteredServices are published to, according to the synchronization
+// strategy
+func getTargetClient(ctx context.Context, strategy primazaiov1alpha1.SynchronizationStrategy, namespace string) (*rest.Config, string, error) {
+	switch strategy {
 	case primazaiov1alpha1.SynchronizationStrategyPush:
 		return workercluster.GetPrimazaKubeconfig(ctx)
 	case primazaiov1alpha1.SynchronizationStrategyPull:
@@ -261,7 +455,7 @@ func (r *ServiceClassReconciler) getTargetClient(ctx context.Context, namespace
 		}
 		return cfg, namespace, nil
 	default:
-		return nil, "", fmt.Errorf("Invalid synchronization strategy %s", r.synchronizationStrategy)
+		return nil, "", fmt.Errorf("Invalid synchronization strategy %s", strategy)
 	}
 }
 
@@ -303,9 +497,11 @@ func (r *ServiceClassReconciler) HandleRegisteredServices(ctx context.Context, s
 	}
 
 	var errorList []error
+	var mappingErrors []error
 	for _, data := range services.Items {
 		var mappings []sed.SEDMapping
-		if mappings, err = ServiceEndpointDefinitionMapping(r.Client, data, *serviceClass); err != nil {
+		if mappings, err = ServiceEndpointDefinitionMapping(r.referencesReader(data), data, *serviceClass); err != nil {
+			setMappedCondition(serviceClass, err)
 			return err
 		}
 
@@ -313,8 +509,12 @@ func (r *ServiceClassReconciler) HandleRegisteredServices(ctx context.Context, s
 		var err error
 		var secret *v1.Secret
 		if rs, secret, err = PrepareRegisteredService(ctx, *serviceClass, mappings, data, target_namespace); err != nil {
-			errorList = append(errorList, err)
-			continue
+			if !isIncomplete(rs) {
+				errorList = append(errorList, err)
+				mappingErrors = append(mappingErrors, fmt.Errorf("%s skipped: %w", data.GetName(), err))
+				continue
+			}
+			mappingErrors = append(mappingErrors, fmt.Errorf("%s registered with missing keys: %w", data.GetName(), err))
 		}
 
 		// modify the registered service
@@ -322,47 +522,131 @@ func (r *ServiceClassReconciler) HandleRegisteredServices(ctx context.Context, s
 			errorList = append(errorList, errs...)
 		}
 	}
+	setMappedCondition(serviceClass, errors.Join(mappingErrors...))
 
 	return errors.Join(errorList...)
 }
 
+// setMappedCondition reports in the ServiceClass's status the outcome of the
+// evaluation of the ServiceEndpointDefinitionMappings
+func setMappedCondition(serviceClass *v1alpha1.ServiceClass, err error) {
+	c := metav1.Condition{
+		Type:   v1alpha1.ServiceClassMappedCondition,
+		Status: metav1.ConditionTrue,
+		Reason: constants.MappingSucceededReason,
+	}
+	if err != nil {
+		c.Status = metav1.ConditionFalse
+		c.Reason = constants.MappingErrorReason
+		c.Message = err.Error()
+
+		var terr *sed.TemplateError
+		if errors.As(err, &terr) {
+			c.Reason = constants.TemplateErrorReason
+		}
+	}
+	meta.SetStatusCondition(&serviceClass.Status.Conditions, c)
+}
+
 func LookupServiceEndpointDescriptor(ctx context.Context, mappings []sed.SEDMapping, service unstructured.Unstructured) ([]v1alpha1.ServiceEndpointDefinitionItem, *v1.Secret, error) {
 	var sedMappings []v1alpha1.ServiceEndpointDefinitionItem
 	var errorList []error
 	secret := &v1.Secret{StringData: map[string]string{}}
 	secret.SetName(fmt.Sprintf("%s-descriptor", service.GetName()))
-	for _, mapping := range mappings {
-		value, err := mapping.ReadKey(ctx)
-		if err != nil {
-			errorList = append(errorList, err)
-			continue
-		}
 
+	keys := map[string]struct{}{}
+	addItem := func(key string, inSecret bool, value string) {
+		keys[key] = struct{}{}
 		item := v1alpha1.ServiceEndpointDefinitionItem{
-			Name:  mapping.Key(),
-			Value: *value,
+			Name:  key,
+			Value: value,
 		}
-		if mapping.InSecret() {
+		if inSecret {
 			item = v1alpha1.ServiceEndpointDefinitionItem{
-				Name: mapping.Key(),
+				Name: key,
 				ValueFromSecret: &v1alpha1.ServiceEndpointDefinitionSecretRef{
 					Name: secret.GetName(),
-					Key:  mapping.Key(),
+					Key:  key,
 				},
 			}
-			secret.StringData[mapping.Key()] = *value
+			secret.StringData[key] = value
 		}
 		sedMappings = append(sedMappings, item)
 	}
 
-	if len(errorList) != 0 {
-		return nil, nil, errors.Join(errorList...)
+	// template mappings are rendered once all the other keys have been read,
+	// and the keys of binding secrets are added last, as mappings take
+	// precedence over them
+	values := map[string]string{}
+	templates := []*sed.SEDTemplateMapping{}
+	provisionedServices := []*sed.SEDProvisionedServiceMapping{}
+	for _, mapping := range mappings {
+		switch m := mapping.(type) {
+		case *sed.SEDTemplateMapping:
+			templates = append(templates, m)
+			continue
+		case *sed.SEDProvisionedServiceMapping:
+			provisionedServices = append(provisionedServices, m)
+			continue
+		case *sed.SEDProviderRefMapping:
+			// values stored in external stores are referenced, not copied
+			ref, err := m.ProviderRef()
+			if err != nil {
+				errorList = append(errorList, &sed.MappingError{Key: mapping.Key(), Err: err})
+				continue
+			}
+			sedMappings = append(sedMappings, v1alpha1.ServiceEndpointDefinitionItem{
+				Name:              mapping.Key(),
+				ValueFromProvider: ref,
+			})
+			continue
+		}
+
+		value, err := mapping.ReadKey(ctx)
+		if err != nil {
+			errorList = append(errorList, &sed.MappingError{Key: mapping.Key(), Err: err})
+			continue
+		}
+		if value == nil {
+			// optional key with no value
+			continue
+		}
+		values[mapping.Key()] = *value
+		addItem(mapping.Key(), mapping.InSecret(), *value)
+	}
+
+	for _, mapping := range templates {
+		value, err := mapping.Render(values)
+		if err != nil {
+			errorList = append(errorList, &sed.MappingError{Key: mapping.Key(), Err: err})
+			continue
+		}
+		addItem(mapping.Key(), mapping.InSecret(), *value)
+	}
+
+	for _, mapping := range provisionedServices {
+		bindingValues, err := mapping.ReadKeys(ctx)
+		if err != nil {
+			errorList = append(errorList, &sed.MappingError{Key: mapping.Key(), Err: err})
+			continue
+		}
+		bindingKeys := make([]string, 0, len(bindingValues))
+		for k := range bindingValues {
+			bindingKeys = append(bindingKeys, k)
+		}
+		slices.Sort(bindingKeys)
+		for _, k := range bindingKeys {
+			if _, found := keys[k]; !found {
+				addItem(k, mapping.InSecret(), bindingValues[k])
+			}
+		}
 	}
 
 	if len(secret.StringData) == 0 {
 		secret = nil
 	}
-	return sedMappings, secret, nil
+	// on error, the items that could be read are returned anyway
+	return sedMappings, secret, errors.Join(errorList...)
 }
 
 func PrepareRegisteredService(
@@ -373,35 +657,65 @@ func PrepareRegisteredService(
 	target_namespace string,
 ) (v1alpha1.RegisteredService, *v1.Secret, error) {
 	l := log.FromContext(ctx)
-	sedMappings, secret, err := LookupServiceEndpointDescriptor(ctx, mappings, data)
+	resource, err := lookupServiceClassResource(serviceClass, data)
 	if err != nil {
-		l.Error(err, "Failed to lookup service endpoint descriptor values",
-			"name", data.GetName(),
-			"namespace", data.GetNamespace(),
-			"gvk", data.GroupVersionKind())
 		return v1alpha1.RegisteredService{}, nil, err
 	}
 
 	rs := v1alpha1.RegisteredService{
 		ObjectMeta: metav1.ObjectMeta{
 			// FIXME(sadlerap): this could cause naming conflicts; we need
-			// to take into account the type of resource somehow.
+			// to take into account the type of resource somehow.  Until
+			// then, RegisteredServices published for other resources are
+			// neither updated nor deleted.
 			Name:      data.GetName(),
 			Namespace: target_namespace,
 			Annotations: map[string]string{
-				constants.ServiceAPIVersionAnnotation:  serviceClass.Spec.Resource.APIVersion,
-				constants.ServiceKindAnnotation:        serviceClass.Spec.Resource.Kind,
+				constants.ServiceAPIVersionAnnotation:  resource.APIVersion,
+				constants.ServiceKindAnnotation:        resource.Kind,
 				constants.ServiceNameAnnotation:        data.GetName(),
 				constants.ServiceNamespaceAnnotation:   data.GetNamespace(),
 				constants.ServiceUIDAnnotation:         string(data.GetUID()),
 				constants.ClusterEnvironmentAnnotation: os.Getenv(constants.PrimazaClusterEnvironmentEnvVar),
 			},
 		},
-		Spec: v1alpha1.RegisteredServiceSpec{
-			ServiceEndpointDefinition: sedMappings,
-			ServiceClassIdentity:      serviceClass.Spec.ServiceClassIdentity,
-			HealthCheck:               serviceClass.Spec.HealthCheck,
-		},
+	}
+
+	// values of resources that are not ready can not be trusted, so the
+	// Service Endpoint Definition is not looked up
+	err = readiness.Check(resource.ReadinessGate, data)
+	if err == nil && resource.ProvisionedService {
+		err = readiness.CheckBinding(data)
+	}
+	if err != nil {
+		l.Info("Service resource is not ready",
+			"name", data.GetName(),
+			"namespace", data.GetNamespace(),
+			"gvk", data.GroupVersionKind(),
+			"reason", err.Error())
+		metav1.SetMetaDataAnnotation(&rs.ObjectMeta, constants.ServiceNotReadyAnnotation, err.Error())
+		return rs, nil, nil
+	}
+
+	sedMappings, secret, err := LookupServiceEndpointDescriptor(ctx, mappings, data)
+	if err != nil {
+		l.Error(err, "Failed to lookup service endpoint descriptor values",
+			"name", data.GetName(),
+			"namespace", data.GetNamespace(),
+			"gvk", data.GroupVersionKind())
+		if serviceClass.Spec.IncompleteResourcePolicy != v1alpha1.ServiceClassIncompleteResourcePolicyRegister {
+			return v1alpha1.RegisteredService{}, nil, err
+		}
+
+		// the resource is registered anyway, reporting the keys that could
+		// not be read
+		metav1.SetMetaDataAnnotation(&rs.ObjectMeta, constants.MissingKeysAnnotation, strings.Join(sed.MissingKeys(err), ","))
+	}
+
+	rs.Spec = v1alpha1.RegisteredServiceSpec{
+		ServiceEndpointDefinition: sedMappings,
+		ServiceClassIdentity:      serviceClass.Spec.ServiceClassIdentity,
+		HealthCheck:               serviceClass.Spec.HealthCheck,
 	}
 
 	rs.Spec.Constraints = &v1alpha1.RegisteredServiceConstraints{
@@ -411,31 +725,85 @@ func PrepareRegisteredService(
 	if secret != nil {
 		secret.SetNamespace(target_namespace)
 	}
-	return rs, secret, nil
+	return rs, secret, err
+}
+
+// referencesReader returns the reader for the secrets and config maps
+// referenced by the resource.  The manager's cache only contains the objects
+// in the agent's namespace, so the objects referenced by cluster-scoped
+// resources are read directly from the API server.
+func (r *ServiceClassReconciler) referencesReader(obj unstructured.Unstructured) client.Reader {
+	if obj.GetNamespace() == "" {
+		return r.apiReader
+	}
+	return r.Client
 }
 
-func ServiceEndpointDefinitionMapping(cli client.Client, obj unstructured.Unstructured, serviceClass v1alpha1.ServiceClass) ([]sed.SEDMapping, error) {
+func ServiceEndpointDefinitionMapping(cli client.Reader, obj unstructured.Unstructured, serviceClass v1alpha1.ServiceClass) ([]sed.SEDMapping, error) {
+	resource, err := lookupServiceClassResource(serviceClass, obj)
+	if err != nil {
+		return nil, err
+	}
+
 	mappings := []sed.SEDMapping{}
 
-	for _, mapping := range serviceClass.Spec.Resource.ServiceEndpointDefinitionMappings.ResourceFields {
+	for _, mapping := range resource.ServiceEndpointDefinitionMappings.ResourceFields {
 		m, err := sed.NewSEDResourceMapping(obj, mapping)
 		if err != nil {
 			return nil, err
 		}
+		mappings = append(mappings, optionalMapping(m, mapping.OptionalMapping))
+	}
+
+	for _, mapping := range resource.ServiceEndpointDefinitionMappings.SecretRefFields {
+		m, err := sed.NewSEDSecretRefMapping(serviceClass.GetNamespace(), obj, cli, mapping)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, optionalMapping(m, mapping.OptionalMapping))
+	}
+
+	for _, mapping := range resource.ServiceEndpointDefinitionMappings.ConfigMapRefFields {
+		m, err := sed.NewSEDConfigMapRefMapping(serviceClass.GetNamespace(), obj, cli, mapping)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, optionalMapping(m, mapping.OptionalMapping))
+	}
+
+	// templates are rendered with the values read by the mappings above
+	sources := append([]sed.SEDMapping{}, mappings...)
+	for _, m := range resource.ServiceEndpointDefinitionMappings.TemplateFields {
+		m, err := sed.NewSEDTemplateMapping(m, sources)
+		if err != nil {
+			return nil, err
+		}
 		mappings = append(mappings, m)
 	}
 
-	for _, m := range serviceClass.Spec.Resource.ServiceEndpointDefinitionMappings.SecretRefFields {
-		m, err := sed.NewSEDSecretRefMapping(serviceClass.GetNamespace(), obj, cli, m)
+	for _, m := range resource.ServiceEndpointDefinitionMappings.ProviderRefFields {
+		m, err := sed.NewSEDProviderRefMapping(obj, m)
 		if err != nil {
 			return nil, err
 		}
 		mappings = append(mappings, m)
 	}
 
+	if resource.ProvisionedService {
+		mappings = append(mappings, sed.NewSEDProvisionedServiceMapping(serviceClass.GetNamespace(), obj, cli))
+	}
+
 	return mappings, nil
 }
 
+// optionalMapping wraps the given mapping if it is optional
+func optionalMapping(m sed.SEDMapping, o v1alpha1.OptionalMapping) sed.SEDMapping {
+	if !o.IsOptional() {
+		return m
+	}
+	return sed.NewSEDOptionalMapping(m, o.Default)
+}
+
 func (r *ServiceClassReconciler) setOwnerReference(ctx context.Context, scclass *v1alpha1.ServiceClass, owner metav1.Object) error {
 	reconcileLog := log.FromContext(ctx)
 	if err := ctrl.SetControllerReference(owner, scclass, r.Client.Scheme()); err != nil {
@@ -451,31 +819,125 @@ func (r *ServiceClassReconciler) setOwnerReference(ctx context.Context, scclass
 
 func (r *ServiceClassReconciler) SetWatchersForResources(ctx context.Context, serviceClass v1alpha1.ServiceClass) error {
 	reconcileLog := log.FromContext(ctx)
-	typemeta := metav1.TypeMeta{
-		Kind:       serviceClass.Spec.Resource.Kind,
-		APIVersion: serviceClass.Spec.Resource.APIVersion,
+	keys := map[string]struct{}{}
+	for _, resource := range serviceClass.Spec.GetResources() {
+		typemeta := metav1.TypeMeta{
+			Kind:       resource.Kind,
+			APIVersion: resource.APIVersion,
+		}
+		gvk := typemeta.GroupVersionKind()
+		mapping, err := r.Client.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			reconcileLog.Error(err, "error on creating mapping")
+			return err
+		}
+		reconcileLog.Info("resource to be watched", "resource", mapping.Resource)
+		if mapping.Scope.Name() == meta.RESTScopeNameRoot {
+			if err := r.checkClusterPermissions(ctx, mapping.Resource, resource); err != nil {
+				reconcileLog.Error(err, "missing permissions on cluster-scoped resource", "resource", mapping.Resource)
+				return err
+			}
+		}
+		keys[informerKey(serviceClass.GetName(), gvk)] = struct{}{}
+		if err = r.RunInformer(ctx, mapping, serviceClass, resource); err != nil {
+			reconcileLog.Error(err, "error running informer")
+			return err
+		}
+	}
+
+	// resource types may have been removed from the ServiceClass
+	r.stopInformers(serviceClass.GetName(), keys)
+	return nil
+}
+
+// informerKey returns the key of the informer watching the given resource
+// type for a ServiceClass
+func informerKey(serviceClassName string, gvk schema.GroupVersionKind) string {
+	return serviceClassName + "/" + gvk.String()
+}
+
+// stopInformers stops the informers of the ServiceClass, except the ones
+// whose key is in keep
+func (r *ServiceClassReconciler) stopInformers(serviceClassName string, keep map[string]struct{}) {
+	for k, i := range r.informers {
+		if !strings.HasPrefix(k, serviceClassName+"/") {
+			continue
+		}
+		if _, ok := keep[k]; ok {
+			continue
+		}
+		i.cancelFunc()
+		delete(r.informers, k)
+	}
+}
+
+// checkClusterPermissions checks that the agent can watch the cluster-scoped
+// resources, and read the secrets they reference in constant namespaces
+func (r *ServiceClassReconciler) checkClusterPermissions(ctx context.Context, gvr schema.GroupVersionResource, resource v1alpha1.ServiceClassResource) error {
+	permissions := []authz.ResourcePermissions{
+		{
+			Verbs:    []string{"get", "list", "watch"},
+			Group:    gvr.Group,
+			Version:  gvr.Version,
+			Resource: gvr.Resource,
+		},
 	}
-	gvk := typemeta.GroupVersionKind()
-	mapping, err := r.Client.RESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	report, err := authz.TestClusterResourcePermissions(ctx, r.config, permissions)
 	if err != nil {
-		reconcileLog.Error(err, "error on creating mapping")
 		return err
 	}
-	reconcileLog.Info("resource to be watched", "resource", mapping.Resource)
-	if err = r.RunInformer(ctx, mapping.Resource, serviceClass); err != nil {
-		reconcileLog.Error(err, "error running informer")
-		return err
+	if len(report.InError) != 0 {
+		return fmt.Errorf("error checking permissions: %v", report.InError)
+	}
+	failed := report.Failed
+
+	for _, m := range resource.ServiceEndpointDefinitionMappings.SecretRefFields {
+		if m.SecretNamespace == nil || m.SecretNamespace.Constant == nil {
+			continue
+		}
+		rr, err := authz.TestResourcePermissions(ctx, r.config, []string{*m.SecretNamespace.Constant}, []authz.ResourcePermissions{
+			{
+				Verbs:    []string{"get"},
+				Version:  "v1",
+				Resource: "secrets",
+			},
+		})
+		if err != nil {
+			return err
+		}
+		nr := rr[*m.SecretNamespace.Constant]
+		if len(nr.InError) != 0 {
+			return fmt.Errorf("error checking permissions: %v", nr.InError)
+		}
+		failed = append(failed, nr.Failed...)
+	}
+
+	if len(failed) != 0 {
+		return fmt.Errorf("permissions not granted: %v", failed)
 	}
 	return nil
 }
 
-func (r *ServiceClassReconciler) RunInformer(ctx context.Context, resource schema.GroupVersionResource, serviceClass v1alpha1.ServiceClass) error {
+func (r *ServiceClassReconciler) RunInformer(ctx context.Context, mapping *meta.RESTMapping, serviceClass v1alpha1.ServiceClass, resource v1alpha1.ServiceClassResource) error {
 	l := log.FromContext(ctx)
+	typemeta := metav1.TypeMeta{
+		Kind:       resource.Kind,
+		APIVersion: resource.APIVersion,
+	}
+	key := informerKey(serviceClass.GetName(), typemeta.GroupVersionKind())
 
 	// check if informer already exists
-	if _, ok := r.informers[serviceClass.GetName()]; ok {
-		l.Info("Informer already exists")
-		return nil
+	if i, ok := r.informers[key]; ok {
+		if i.generation == serviceClass.GetGeneration() {
+			l.Info("Informer already exists")
+			return nil
+		}
+
+		// the informer's event handlers use the ServiceClass's mappings and
+		// selectors, so it is restarted when the ServiceClass is updated
+		l.Info("ServiceClass updated, restarting informer", "generation", serviceClass.GetGeneration())
+		i.cancelFunc()
+		delete(r.informers, key)
 	}
 	clusterConfig, err := rest.InClusterConfig()
 	if err != nil {
@@ -487,8 +949,20 @@ func (r *ServiceClassReconciler) RunInformer(ctx context.Context, resource schem
 		l.Info("failed creating cluster config")
 		panic(err)
 	}
-	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(clusterClient, time.Minute, serviceClass.Namespace, nil)
-	i := factory.ForResource(resource).Informer()
+	opts, err := resourceListOptions(resource)
+	if err != nil {
+		return err
+	}
+	// cluster-scoped resources are watched in all namespaces
+	namespace := serviceClass.Namespace
+	if mapping.Scope.Name() == meta.RESTScopeNameRoot {
+		namespace = metav1.NamespaceAll
+	}
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(clusterClient, time.Minute, namespace, func(o *metav1.ListOptions) {
+		o.LabelSelector = opts.LabelSelector
+		o.FieldSelector = opts.FieldSelector
+	})
+	i := factory.ForResource(mapping.Resource).Informer()
 	ictx, fc := context.WithCancel(ctx)
 
 	var synced atomic.Bool
@@ -499,6 +973,9 @@ func (r *ServiceClassReconciler) RunInformer(ctx context.Context, resource schem
 				return
 			}
 			serviceClassResource := obj.(*unstructured.Unstructured)
+			if isIgnored(*serviceClassResource) || isAnnotatedService(*serviceClassResource) {
+				return
+			}
 			if err := r.CreateOrUpdateRegisteredService(ictx, *serviceClassResource, serviceClass); err != nil {
 				return
 			}
@@ -508,6 +985,18 @@ func (r *ServiceClassReconciler) RunInformer(ctx context.Context, resource schem
 				return
 			}
 			serviceClassResource := future.(*unstructured.Unstructured)
+			if isAnnotatedService(*serviceClassResource) {
+				// the RegisteredService is now owned by the
+				// AnnotatedServiceReconciler
+				return
+			}
+			if isIgnored(*serviceClassResource) {
+				// the resource may have opted out after being registered
+				if err := r.DeleteRegisteredService(ictx, *serviceClassResource, serviceClass); err != nil {
+					return
+				}
+				return
+			}
 			if err := r.CreateOrUpdateRegisteredService(ictx, *serviceClassResource, serviceClass); err != nil {
 				return
 			}
@@ -516,7 +1005,14 @@ func (r *ServiceClassReconciler) RunInformer(ctx context.Context, resource schem
 			if !synced.Load() {
 				return
 			}
-			if err := r.DeleteRegisteredService(ictx, serviceClass); err != nil {
+			if d, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = d.Obj
+			}
+			serviceClassResource, ok := obj.(*unstructured.Unstructured)
+			if !ok || isAnnotatedService(*serviceClassResource) {
+				return
+			}
+			if err := r.DeleteRegisteredService(ictx, *serviceClassResource, serviceClass); err != nil {
 				return
 			}
 		},
@@ -525,15 +1021,15 @@ func (r *ServiceClassReconciler) RunInformer(ctx context.Context, resource schem
 		return err
 	}
 
-	l.Info("run informer", "GroupVersionResource", resource)
+	l.Info("run informer", "GroupVersionResource", mapping.Resource)
 
-	li := informer{informer: i, ctx: ictx, cancelFunc: fc}
-	r.informers[serviceClass.GetName()] = li
+	li := informer{informer: i, ctx: ictx, cancelFunc: fc, generation: serviceClass.GetGeneration()}
+	r.informers[key] = li
 	go li.run()
 
 	if !cache.WaitForCacheSync(ctx.Done(), i.HasSynced) {
 		fc()
-		delete(r.informers, serviceClass.GetName())
+		delete(r.informers, key)
 		return fmt.Errorf("could not sync cache")
 	}
 
@@ -547,7 +1043,7 @@ func (r *ServiceClassReconciler) CreateOrUpdateRegisteredService(ctx context.Con
 	var mappings []sed.SEDMapping
 	var err error
 
-	if mappings, err = ServiceEndpointDefinitionMapping(r.Client, obj, serviceClass); err != nil {
+	if mappings, err = ServiceEndpointDefinitionMapping(r.referencesReader(obj), obj, serviceClass); err != nil {
 		return err
 	}
 	config, target_namespace, err := r.getTargetClient(ctx, serviceClass.Namespace)
@@ -564,14 +1060,13 @@ func (r *ServiceClassReconciler) CreateOrUpdateRegisteredService(ctx context.Con
 	errs := []error{err}
 	var rs v1alpha1.RegisteredService
 	var secret *v1.Secret
-	if rs, secret, err = PrepareRegisteredService(ctx, serviceClass, mappings, obj, target_namespace); err != nil {
+	if rs, secret, err = PrepareRegisteredService(ctx, serviceClass, mappings, obj, target_namespace); err != nil && !isIncomplete(rs) {
 		return err
 	}
-	spec := rs.Spec
-	op, err := controllerutil.CreateOrUpdate(ctx, target_client, &rs, func() error {
-		rs.Spec = spec
-		return nil
-	})
+	if isNotReady(rs) {
+		return markRegisteredServiceNotReady(ctx, target_client, rs)
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, target_client, &rs, mutateRegisteredService(&rs))
 	if err != nil {
 		l.Error(err, "Failed to create or update registered service")
 		errs = append(errs, err)
@@ -579,20 +1074,14 @@ func (r *ServiceClassReconciler) CreateOrUpdateRegisteredService(ctx context.Con
 		l.Info("Wrote registered service", "registered service", rs.Name, "namespace", rs.Namespace, "operation", op)
 	}
 	if secret != nil {
-		data := secret.StringData
-		_, err := controllerutil.CreateOrUpdate(ctx, target_client, secret, func() error {
-			secret.StringData = data
-
-			return controllerutil.SetOwnerReference(&rs, secret, target_client.Scheme())
-		})
-		errs = append(errs, err)
+		errs = append(errs, writeDescriptorSecret(ctx, target_client, rs, secret))
 	}
 	return errors.Join(errs...)
 }
 
-func (r *ServiceClassReconciler) DeleteRegisteredService(ctx context.Context, serviceClass v1alpha1.ServiceClass) error {
+func (r *ServiceClassReconciler) DeleteRegisteredService(ctx context.Context, obj unstructured.Unstructured, serviceClass v1alpha1.ServiceClass) error {
 	l := log.FromContext(ctx)
-	config, _, err := r.getTargetClient(ctx, serviceClass.Namespace)
+	config, target_namespace, err := r.getTargetClient(ctx, serviceClass.Namespace)
 	if err != nil {
 		return err
 	}
@@ -605,20 +1094,74 @@ func (r *ServiceClassReconciler) DeleteRegisteredService(ctx context.Context, se
 	}
 	l.Info("remote cluster", "address", config.Host)
 
-	registeredService := v1alpha1.RegisteredService{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: serviceClass.Name,
-		},
-	}
-	if err = target_client.Delete(ctx, &registeredService, &client.DeleteOptions{}); !apierrors.IsNotFound(err) {
-		return err
-	}
-	return nil
+	key := types.NamespacedName{Name: obj.GetName(), Namespace: target_namespace}
+	return deletePublishedRegisteredService(ctx, target_client, key, obj.GetAPIVersion(), obj.GetKind(), obj.GetNamespace())
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ServiceClassReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Secrets are watched so that rotated values read through
+	// SecretRefFields mappings are pushed to Primaza's Control Plane
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v1alpha1.ServiceClass{}).
+		Watches(&v1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.reconcileOnSecretUpdate)).
+		Watches(&v1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.reconcileOnConfigMapUpdate)).
 		Complete(r)
 }
+
+// reconcileOnSecretUpdate maps a secret to the ServiceClasses in its namespace
+// reading values from secrets, binding secrets included.  Descriptor secrets
+// owned by RegisteredServices are ignored, as they are written by the agent
+// itself.
+func (r *ServiceClassReconciler) reconcileOnSecretUpdate(ctx context.Context, a client.Object) []reconcile.Request {
+	l := log.FromContext(ctx).WithValues("secret", a.GetName())
+	for _, o := range a.GetOwnerReferences() {
+		if o.Kind == "RegisteredService" {
+			return []reconcile.Request{}
+		}
+	}
+
+	var scl v1alpha1.ServiceClassList
+	if err := r.List(ctx, &scl, client.InNamespace(a.GetNamespace())); err != nil {
+		l.Error(err, "unable to list the ServiceClasses to reconcile for secret update")
+		return []reconcile.Request{}
+	}
+
+	requests := []reconcile.Request{}
+	for _, sc := range scl.Items {
+		if slices.ContainsFunc(sc.Spec.GetResources(), func(r v1alpha1.ServiceClassResource) bool {
+			return len(r.ServiceEndpointDefinitionMappings.SecretRefFields) != 0 || r.ProvisionedService
+		}) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+				Namespace: sc.Namespace,
+				Name:      sc.Name,
+			}})
+		}
+	}
+	return requests
+}
+
+// reconcileOnConfigMapUpdate maps a config map to the ServiceClasses in its
+// namespace reading values from config maps
+func (r *ServiceClassReconciler) reconcileOnConfigMapUpdate(ctx context.Context, a client.Object) []reconcile.Request {
+	l := log.FromContext(ctx).WithValues("config map", a.GetName())
+
+	var scl v1alpha1.ServiceClassList
+	if err := r.List(ctx, &scl, client.InNamespace(a.GetNamespace())); err != nil {
+		l.Error(err, "unable to list the ServiceClasses to reconcile for config map update")
+		return []reconcile.Request{}
+	}
+
+	requests := []reconcile.Request{}
+	for _, sc := range scl.Items {
+		if slices.ContainsFunc(sc.Spec.GetResources(), func(r v1alpha1.ServiceClassResource) bool {
+			return len(r.ServiceEndpointDefinitionMappings.ConfigMapRefFields) != 0
+		}) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+				Namespace: sc.Namespace,
+				Name:      sc.Name,
+			}})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	primazaiov1alpha1 "github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/primaza/constants"
+	"github.com/primaza/primaza/pkg/primaza/inventory"
+)
+
+//+kubebuilder:rbac:groups="",namespace=system,resources=configmaps,verbs=get;list;watch
+
+// InventoryReconciler imports the RegisteredServices listed in inventory
+// ConfigMaps, i.e. the ConfigMaps labeled with `primaza.io/inventory=true`.
+// Each ConfigMap owns the RegisteredServices imported from it.
+type InventoryReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+func (r *InventoryReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	l := log.FromContext(ctx)
+	l.Info("Reconciling inventory")
+
+	cm := corev1.ConfigMap{}
+	if err := r.Get(ctx, req.NamespacedName, &cm); err != nil {
+		// RegisteredServices owned by deleted ConfigMaps are garbage collected
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !cm.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	inv := &inventory.Inventory{}
+	if isInventory(&cm) {
+		var err error
+		if inv, err = inventory.Parse([]byte(cm.Data[inventory.ConfigMapKey])); err != nil {
+			// imported services are left untouched until the inventory is fixed
+			l.Error(err, "Invalid inventory", "key", inventory.ConfigMapKey)
+			return ctrl.Result{}, nil
+		}
+	}
+
+	// ConfigMap names can be longer than label values, retrying is pointless
+	source := inventory.ConfigMapSource(req.Name)
+	if err := inventory.ValidateSource(source); err != nil {
+		l.Error(err, "Invalid inventory ConfigMap name")
+		return ctrl.Result{}, nil
+	}
+
+	importer := inventory.Importer{
+		Client:    r.Client,
+		Namespace: req.Namespace,
+		Source:    source,
+		Owner:     &cm,
+	}
+	return ctrl.Result{}, importer.Sync(ctx, *inv)
+}
+
+func isInventory(o client.Object) bool {
+	return o.GetLabels()[constants.PrimazaInventoryLabel] == "true"
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *InventoryReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// ConfigMaps losing the label are reconciled too, so that the services
+	// imported from them are deleted
+	inventories := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return isInventory(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return isInventory(e.ObjectOld) || isInventory(e.ObjectNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return isInventory(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return isInventory(e.Object)
+		},
+	}
+
+	// imported RegisteredServices are watched, so that they are restored
+	// when deleted or modified
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("inventory").
+		For(&corev1.ConfigMap{}, builder.WithPredicates(inventories)).
+		Owns(&primazaiov1alpha1.RegisteredService{}).
+		Complete(r)
+}
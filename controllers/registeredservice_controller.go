@@ -34,6 +34,7 @@ import (
 	"github.com/primaza/primaza/api/v1alpha1"
 	primazaiov1alpha1 "github.com/primaza/primaza/api/v1alpha1"
 	"github.com/primaza/primaza/pkg/envtag"
+	"github.com/primaza/primaza/pkg/primaza/constants"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -313,9 +314,7 @@ func (r *RegisteredServiceReconciler) handleHealthcheck(ctx context.Context, rs
 	l.Info("Job status", "completed", completed, "failed", failed)
 	if completed {
 		// only keep it in 'Claimed' if the healthcheck succeeded
-		if rs.Status.State != primazaiov1alpha1.RegisteredServiceStateClaimed {
-			rs.Status.State = primazaiov1alpha1.RegisteredServiceStateAvailable
-		}
+		rs.Status.State = rs.ClaimsState()
 	} else if failed {
 		rs.Status.State = primazaiov1alpha1.RegisteredServiceStateUnreachable
 	} else {
@@ -412,11 +411,17 @@ func (r *RegisteredServiceReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		}
 
 		// Since we don't have a healthcheck, we can be in one of two states:
-		// Available or Claimed.  Explicitly set to Available if not Claimed,
-		// since this also lets us clean up healthcheck removal.
-		if rs.Status.State != primazaiov1alpha1.RegisteredServiceStateClaimed {
-			rs.Status.State = primazaiov1alpha1.RegisteredServiceStateAvailable
-		}
+		// Available or Claimed, depending on the claims bound to the service.
+		// This also lets us clean up healthcheck removal.
+		rs.Status.State = rs.ClaimsState()
+	}
+
+	// Services registered with missing keys, or whose resource is not ready,
+	// can not be claimed until all their keys are known and the resource is
+	// ready again. Claimed services are left Claimed, so that services
+	// claimed before claims were tracked are not reported Available later.
+	if rs.Status.State == primazaiov1alpha1.RegisteredServiceStateAvailable && isIncompleteOrNotReady(rs) {
+		rs.Status.State = primazaiov1alpha1.RegisteredServiceStateUnknown
 	}
 
 	if rs.Status.State == primazaiov1alpha1.RegisteredServiceStateAvailable {
@@ -447,6 +452,15 @@ func (r *RegisteredServiceReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	return ctrl.Result{}, nil
 }
 
+func isIncompleteOrNotReady(rs primazaiov1alpha1.RegisteredService) bool {
+	for _, a := range []string{constants.MissingKeysAnnotation, constants.ServiceNotReadyAnnotation} {
+		if _, ok := rs.GetAnnotations()[a]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *RegisteredServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
@@ -123,6 +123,41 @@ var _ = Describe("Registered Service reconciler tests", func() {
 			Expect(rs.Status.State).To(Equal(v1alpha1.RegisteredServiceStateAvailable))
 		})
 
+		DescribeTable("Claims capacity state",
+			func(sharing *v1alpha1.RegisteredServiceSharing, claims []string, state v1alpha1.RegisteredServiceState) {
+				_, err := ctrl.CreateOrUpdate(ctx, rsController.Client, &rs, func() error {
+					rs.Spec.HealthCheck = nil
+					rs.Spec.Sharing = sharing
+					return nil
+				})
+				Expect(err).NotTo(HaveOccurred())
+				rs.Status.Claims = claims
+				err = client.Status().Update(ctx, &rs)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = rsController.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+				Expect(err).NotTo(HaveOccurred())
+
+				err = client.Get(ctx, namespacedName, &rs)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(rs.Status.State).To(Equal(state))
+			},
+			Entry("exclusive and unclaimed", nil, nil, v1alpha1.RegisteredServiceStateAvailable),
+			Entry("exclusive and claimed", nil, []string{"a"}, v1alpha1.RegisteredServiceStateClaimed),
+			Entry("shared without limit",
+				&v1alpha1.RegisteredServiceSharing{Mode: v1alpha1.RegisteredServiceSharingModeShared},
+				[]string{"a", "b", "c"},
+				v1alpha1.RegisteredServiceStateAvailable),
+			Entry("shared below capacity",
+				&v1alpha1.RegisteredServiceSharing{Mode: v1alpha1.RegisteredServiceSharingModeShared, MaxClaims: 2},
+				[]string{"a"},
+				v1alpha1.RegisteredServiceStateAvailable),
+			Entry("shared at capacity",
+				&v1alpha1.RegisteredServiceSharing{Mode: v1alpha1.RegisteredServiceSharingModeShared, MaxClaims: 2},
+				[]string{"a", "b"},
+				v1alpha1.RegisteredServiceStateClaimed),
+		)
+
 		It("should set state to unknown when a healthcheck is defined", func() {
 			_, err := rsController.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
 			Expect(err).NotTo(HaveOccurred())
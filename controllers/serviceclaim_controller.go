@@ -18,6 +18,8 @@ package controllers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"reflect"
@@ -28,7 +30,9 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -40,31 +44,42 @@ import (
 	"github.com/primaza/primaza/api/v1alpha1"
 	primazaiov1alpha1 "github.com/primaza/primaza/api/v1alpha1"
 	"github.com/primaza/primaza/pkg/envtag"
+	"github.com/primaza/primaza/pkg/primaza/claim"
 	"github.com/primaza/primaza/pkg/primaza/clustercontext"
 	"github.com/primaza/primaza/pkg/primaza/constants"
 	"github.com/primaza/primaza/pkg/primaza/controlplane"
+	"github.com/primaza/primaza/pkg/primaza/sed"
 )
 
 // ServiceClaimReconciler reconciles a ServiceClaim object
 type ServiceClaimReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
-	Mapper meta.RESTMapper
+	Scheme   *runtime.Scheme
+	Mapper   meta.RESTMapper
+	Ranker   *claim.Ranker
+	Recorder record.EventRecorder
 }
 
 const ServiceClaimFinalizer = "serviceclaims.primaza.io/finalizer"
 
+// maxDiagnosisReportLength bounds the size of the report stored in the
+// ServiceClaim's Matched condition
+const maxDiagnosisReportLength = 1024
+
 func NewServiceClaimReconciler(mgr ctrl.Manager) *ServiceClaimReconciler {
 	return &ServiceClaimReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		Mapper: mgr.GetRESTMapper(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Mapper:   mgr.GetRESTMapper(),
+		Ranker:   claim.NewDefaultRanker(),
+		Recorder: mgr.GetEventRecorderFor("serviceclaim-controller"),
 	}
 }
 
 //+kubebuilder:rbac:groups=primaza.io,namespace=system,resources=serviceclaims,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=primaza.io,namespace=system,resources=serviceclaims/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=primaza.io,namespace=system,resources=serviceclaims/finalizers,verbs=update
+//+kubebuilder:rbac:groups="",namespace=system,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -105,6 +120,20 @@ func (r *ServiceClaimReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, nil
 	}
 
+	// dry run is not applied to already resolved claims, as it would unbind them
+	if isDryRun(sclaim) && sclaim.Status.State != primazaiov1alpha1.ServiceClaimStateResolved {
+		l.Info("resolving service claim in dry run mode")
+		if err := r.ensureServiceClaimIsInitialized(ctx, &sclaim); err != nil {
+			l.Error(err, "error initializing the ServiceClaim")
+			return ctrl.Result{}, err
+		}
+		if err := r.processDryRunServiceClaim(ctx, sclaim); err != nil {
+			l.Error(err, "error processing ServiceClaim in dry run mode")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
 	// add finalizer if needed
 	l.Info("Add Finalizer if needed")
 	if controllerutil.AddFinalizer(&sclaim, ServiceClaimFinalizer) {
@@ -149,6 +178,7 @@ func (r *ServiceClaimReconciler) ensureServiceClaimIsInitialized(ctx context.Con
 
 func (r *ServiceClaimReconciler) processClaim(ctx context.Context, req ctrl.Request, sclaim primazaiov1alpha1.ServiceClaim) error {
 	l := log.FromContext(ctx)
+	sclaim.Status.DryRun = nil
 
 	var rsl primazaiov1alpha1.RegisteredServiceList
 	lo := client.ListOptions{Namespace: req.NamespacedName.Namespace}
@@ -164,6 +194,85 @@ func (r *ServiceClaimReconciler) processClaim(ctx context.Context, req ctrl.Requ
 	return nil
 }
 
+func isDryRun(sclaim primazaiov1alpha1.ServiceClaim) bool {
+	return sclaim.Annotations[constants.DryRunAnnotation] == "true"
+}
+
+// processDryRunServiceClaim resolves the ServiceClaim without binding it:
+// RegisteredServices are not updated and nothing is pushed to application
+// namespaces. The outcome is reported in the ServiceClaim's status.
+func (r *ServiceClaimReconciler) processDryRunServiceClaim(ctx context.Context, sclaim primazaiov1alpha1.ServiceClaim) error {
+	l := log.FromContext(ctx)
+
+	if err := claim.ValidateVersionRanges(sclaim.Spec); err != nil {
+		return r.markServiceClaimInvalid(ctx, &sclaim, err)
+	}
+
+	var rsl primazaiov1alpha1.RegisteredServiceList
+	if err := r.List(ctx, &rsl, client.InNamespace(sclaim.Namespace)); err != nil {
+		l.Info("unable to retrieve RegisteredServiceList", "error", err)
+		return err
+	}
+
+	ranked, d, err := r.rankRegisteredServices(ctx, sclaim, rsl.Items)
+	if err != nil {
+		return err
+	}
+	setMatchedCondition(&sclaim, *d)
+
+	c := metav1.Condition{
+		LastTransitionTime: metav1.Now(),
+		Type:               string(primazaiov1alpha1.ServiceClaimConditionReady),
+		Status:             metav1.ConditionFalse,
+		Reason:             constants.DryRunReason,
+	}
+	sclaim.Status.State = primazaiov1alpha1.ServiceClaimStatePending
+	sclaim.Status.DryRun = &primazaiov1alpha1.ServiceClaimDryRunResult{}
+	if len(ranked) == 0 {
+		c.Message = "dry run: no registered service can be claimed: " + d.Summary()
+		meta.SetStatusCondition(&sclaim.Status.Conditions, c)
+		return r.updateServiceClaimStatus(ctx, &sclaim)
+	}
+
+	rs := ranked[0].RegisteredService
+	secret, err := r.getServiceEndpointDefinition(ctx, sclaim, rs)
+	if err != nil {
+		l.Error(err, "error baking the ServiceEndpointDefinition", "registered-service", rs.Name)
+		return err
+	}
+
+	keys := make([]string, 0, len(secret.StringData))
+	for k := range secret.StringData {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	sclaim.Status.DryRun = &primazaiov1alpha1.ServiceClaimDryRunResult{
+		RegisteredService: &corev1.ObjectReference{
+			Name: rs.Name,
+			UID:  rs.UID,
+		},
+		ResolvedVersion: claim.ResolvedVersion(sclaim.Spec, rs),
+		SecretKeys:      keys,
+	}
+	c.Message = fmt.Sprintf("dry run: would be bound to registered service %s", rs.Name)
+	for _, k := range sclaim.Spec.ServiceEndpointDefinitionKeys {
+		if !slices.Contains(keys, k) {
+			c.Message = fmt.Sprintf("dry run: registered service %s does not provide key %s", rs.Name, k)
+			break
+		}
+	}
+	meta.SetStatusCondition(&sclaim.Status.Conditions, c)
+	meta.SetStatusCondition(&sclaim.Status.Conditions, metav1.Condition{
+		LastTransitionTime: metav1.Now(),
+		Type:               string(primazaiov1alpha1.ServiceClaimConditionRanked),
+		Status:             metav1.ConditionTrue,
+		Reason:             constants.CandidateSelectedReason,
+		Message:            claim.DescribeSelection(ranked),
+	})
+	return r.updateServiceClaimStatus(ctx, &sclaim)
+}
+
 func (r *ServiceClaimReconciler) processClaimMarkedForDeletion(ctx context.Context, req ctrl.Request, sclaim primazaiov1alpha1.ServiceClaim) error {
 	l := log.FromContext(ctx)
 	errs := []error{}
@@ -173,27 +282,20 @@ func (r *ServiceClaimReconciler) processClaimMarkedForDeletion(ctx context.Conte
 		l.Info("unable to retrieve RegisteredServiceList", "error", err)
 		errs = append(errs, client.IgnoreNotFound(err))
 	}
-	var registeredServiceFound bool
-	var registeredService primazaiov1alpha1.RegisteredService
-	for _, rs := range rsl.Items {
-		// Check if the ServiceClassIdentity given in ServiceClaim is a subset of
-		// ServiceClassIdentity given in the RegisteredService
-		if checkSCISubset(sclaim.Spec.ServiceClassIdentity, rs.Spec.ServiceClassIdentity) &&
-			(rs.Spec.Constraints == nil ||
-				envtag.Match(sclaim.Spec.Target.EnvironmentTag, rs.Spec.Constraints.Environments)) {
-			registeredServiceFound = true
-			registeredService = rs
-			break
-		}
-	}
 
 	if err := r.DeleteServiceBindingsAndSecret(ctx, req, sclaim); err != nil {
 		l.Error(err, "unable to delete service binding and secret", "Service Binding", sclaim.Name)
 		errs = append(errs, err)
 	}
 
-	if registeredServiceFound {
-		if err := r.changeServiceState(ctx, registeredService, primazaiov1alpha1.RegisteredServiceStateAvailable); err != nil {
+	env, err := r.getServiceClaimEnvironment(ctx, sclaim)
+	if err != nil {
+		l.Info("unable to get environment from cluster environment", "error", err)
+		env = sclaim.Spec.Target.EnvironmentTag
+	}
+
+	if registeredService := findClaimedRegisteredService(rsl.Items, sclaim, env); registeredService != nil {
+		if err := r.releaseRegisteredService(ctx, *registeredService, sclaim.Status.ClaimID); err != nil {
 			l.Error(err, "unable to update the RegisteredService", "RegisteredService", registeredService)
 			errs = append(errs, err)
 		}
@@ -201,24 +303,36 @@ func (r *ServiceClaimReconciler) processClaimMarkedForDeletion(ctx context.Conte
 	return errors.Join(errs...)
 }
 
-// Ref. https://stackoverflow.com/a/18879994/547840
-func checkSCISubset(serviceClaim, registeredService []v1alpha1.ServiceClassIdentityItem) bool {
-	set := make(map[v1alpha1.ServiceClassIdentityItem]int)
-	for _, value := range registeredService {
-		set[value] += 1
+// findClaimedRegisteredService looks for the RegisteredService bound to the
+// ServiceClaim, first by claim ID and then by the reference stored in the
+// ServiceClaim's status. As a last resort, it looks for a Claimed
+// RegisteredService not tracking its claims that matches the ServiceClaim.
+func findClaimedRegisteredService(rss []primazaiov1alpha1.RegisteredService, sclaim primazaiov1alpha1.ServiceClaim, env string) *primazaiov1alpha1.RegisteredService {
+	if sclaim.Status.ClaimID != "" {
+		for _, rs := range rss {
+			if rs.IsClaimedBy(sclaim.Status.ClaimID) {
+				return &rs
+			}
+		}
+	}
+
+	if ref := sclaim.Status.RegisteredService; ref != nil {
+		for _, rs := range rss {
+			if rs.Name == ref.Name && rs.UID == ref.UID {
+				return &rs
+			}
+		}
 	}
 
-	for _, value := range serviceClaim {
-		if count, found := set[value]; !found {
-			return false
-		} else if count < 1 {
-			return false
-		} else {
-			set[value] = count - 1
+	for _, rs := range rss {
+		if rs.Status.State == primazaiov1alpha1.RegisteredServiceStateClaimed &&
+			len(rs.Status.Claims) == 0 &&
+			matchRegisteredService(sclaim, rs, env) {
+			return &rs
 		}
 	}
 
-	return true
+	return nil
 }
 
 func (r *ServiceClaimReconciler) extractServiceEndpointDefinition(
@@ -231,25 +345,36 @@ func (r *ServiceClaimReconciler) extractServiceEndpointDefinition(
 	count := 0
 
 	// loop over the ServiceEndpointDefinition array part of RegisteredService
-	for _, sed := range rs.Spec.ServiceEndpointDefinition {
+	for _, item := range rs.Spec.ServiceEndpointDefinition {
 		// check if the value is non-empty
-		if sed.Value != "" {
+		if item.Value != "" {
 			// check if the ServiceEndpointDefinitionKeys part of ServiceClaim has the current
 			// SED name in the RegisteredService
-			if slices.Contains(sedKeys, sed.Name) {
-				secret.StringData[sed.Name] = sed.Value
+			if slices.Contains(sedKeys, item.Name) {
+				secret.StringData[item.Name] = item.Value
 				count++
 			}
-		} else if k := sed.ValueFromSecret.Key; k != "" { // check value if the key is non-empty
-			if slices.Contains(sedKeys, sed.Name) {
+		} else if item.ValueFromSecret != nil && item.ValueFromSecret.Key != "" { // check value if the key is non-empty
+			if slices.Contains(sedKeys, item.Name) {
 				sec := &corev1.Secret{}
-				nn := types.NamespacedName{Namespace: namespace, Name: sed.ValueFromSecret.Name}
+				nn := types.NamespacedName{Namespace: namespace, Name: item.ValueFromSecret.Name}
 				if err := r.Get(ctx, nn, sec); err != nil {
 					l.Info("unable to retrieve Secret", "error", err, "secret", nn)
 					continue
 				}
 
-				secret.StringData[sed.Name] = string(sec.Data[k])
+				secret.StringData[item.Name] = string(sec.Data[item.ValueFromSecret.Key])
+				count++
+			}
+		} else if item.ValueFromProvider != nil { // resolve values stored in external stores
+			if slices.Contains(sedKeys, item.Name) {
+				v, err := sed.ReadProviderValue(ctx, *item.ValueFromProvider)
+				if err != nil {
+					l.Info("unable to read value from provider", "error", err, "provider", item.ValueFromProvider.Name)
+					continue
+				}
+
+				secret.StringData[item.Name] = *v
 				count++
 			}
 		}
@@ -257,13 +382,44 @@ func (r *ServiceClaimReconciler) extractServiceEndpointDefinition(
 	return count, nil
 }
 
-func (r *ServiceClaimReconciler) changeServiceState(ctx context.Context, rs primazaiov1alpha1.RegisteredService, state primazaiov1alpha1.RegisteredServiceState) error {
-	rs.Status.State = state
-	if err := r.Status().Update(ctx, &rs); err != nil {
-		return err
+// claimRegisteredService binds the ServiceClaim with the given ID to the
+// RegisteredService, if not already bound
+func (r *ServiceClaimReconciler) claimRegisteredService(ctx context.Context, rs primazaiov1alpha1.RegisteredService, claimID string) error {
+	if rs.IsClaimedBy(claimID) {
+		return nil
 	}
 
-	return nil
+	if !rs.AddClaim(claimID) {
+		return fmt.Errorf("registered service %s has no claim capacity left", rs.Name)
+	}
+
+	return r.Status().Update(ctx, &rs)
+}
+
+// releaseRegisteredService unbinds the ServiceClaim with the given ID from
+// the RegisteredService
+func (r *ServiceClaimReconciler) releaseRegisteredService(ctx context.Context, rs primazaiov1alpha1.RegisteredService, claimID string) error {
+	if !rs.RemoveClaim(claimID) {
+		// services claimed before claims were tracked have no claim IDs in status
+		if len(rs.Status.Claims) != 0 || rs.Status.State != primazaiov1alpha1.RegisteredServiceStateClaimed {
+			return nil
+		}
+		rs.Status.State = primazaiov1alpha1.RegisteredServiceStateAvailable
+	}
+
+	return r.Status().Update(ctx, &rs)
+}
+
+// getServiceClaimEnvironment returns the environment targeted by the ServiceClaim
+func (r *ServiceClaimReconciler) getServiceClaimEnvironment(ctx context.Context, sclaim primazaiov1alpha1.ServiceClaim) (string, error) {
+	return claim.Environment(ctx, r.Client, sclaim)
+}
+
+// matchRegisteredService returns true if the RegisteredService satisfies the
+// ServiceClaim's ServiceClassIdentity and can be used in the given environment
+func matchRegisteredService(sclaim primazaiov1alpha1.ServiceClaim, rs primazaiov1alpha1.RegisteredService, env string) bool {
+	return claim.MatchServiceClassIdentity(sclaim.Spec, rs.Spec.ServiceClassIdentity) &&
+		envtag.Match(env, rs.Spec.GetEnvironmentConstraints())
 }
 
 func (r *ServiceClaimReconciler) getEnvironmentFromClusterEnvironment(
@@ -332,6 +488,12 @@ func (r *ServiceClaimReconciler) processResolvedServiceClaim(
 		return err
 	}
 
+	if rs.Status.State == primazaiov1alpha1.RegisteredServiceStateUnreachable &&
+		sclaim.Spec.FailoverPolicy == primazaiov1alpha1.ServiceClaimFailoverPolicyOnUnreachable {
+		l.Info("claimed registered service is unreachable, failing over", "registered-service", rs.Name)
+		return r.failoverServiceClaim(ctx, sclaim, rs)
+	}
+
 	// bake the ServiceEndpointDefinition Secret
 	secret, err := r.getServiceEndpointDefinition(ctx, sclaim, rs)
 	if err != nil {
@@ -344,14 +506,15 @@ func (r *ServiceClaimReconciler) processResolvedServiceClaim(
 		Name: rs.Name,
 		UID:  rs.UID,
 	}
+	sclaim.Status.ResolvedVersion = claim.ResolvedVersion(sclaim.Spec, rs)
 	if err := r.updateServiceClaimStatus(ctx, &sclaim); err != nil {
 		l.Error(err, "error updating the ServiceClaim",
 			"registered-service", rs, "service-claim", sclaim)
 		return err
 	}
 
-	// Update RegisteredService status to Claimed to avoid raise conditions
-	if err := r.changeServiceState(ctx, rs, primazaiov1alpha1.RegisteredServiceStateClaimed); err != nil {
+	// Bind the ServiceClaim to the RegisteredService to avoid raise conditions
+	if err := r.claimRegisteredService(ctx, rs, sclaim.Status.ClaimID); err != nil {
 		l.Error(err, "error updating the RegisteredService", "registered-service", rs, "service-claim", sclaim)
 		return err
 	}
@@ -361,18 +524,20 @@ func (r *ServiceClaimReconciler) processResolvedServiceClaim(
 		Name: rs.Name,
 		UID:  rs.UID,
 	}
+	sclaim.Status.ResolvedVersion = claim.ResolvedVersion(sclaim.Spec, rs)
 	if err := r.pushToClusterEnvironments(ctx, sclaim, secret); err != nil {
 		l.Error(err,
 			"error pushing the ServiceBinding and secret to the cluster environments",
 			"registered-service", rs, "service-claim", sclaim)
-		// Update RegisteredService status back to Available
-		if err := r.changeServiceState(ctx, rs, primazaiov1alpha1.RegisteredServiceStateAvailable); err != nil {
+		// Release the RegisteredService
+		if err := r.releaseRegisteredService(ctx, rs, sclaim.Status.ClaimID); err != nil {
 			l.Error(err,
 				"error updating the RegisteredService with details on failed push of Service Binding",
 				"registered-service", rs, "service-claim", sclaim)
 		}
 		return err
 	}
+	r.recordSecretRotation(&sclaim, secret)
 
 	if err := r.updateServiceClaimStatus(ctx, &sclaim); err != nil {
 		l.Error(err, "error updating the ServiceClaim",
@@ -383,6 +548,130 @@ func (r *ServiceClaimReconciler) processResolvedServiceClaim(
 	return nil
 }
 
+// recordSecretRotation stores the checksum of the pushed Service Endpoint
+// Definition secret in the ServiceClaim's status.  When the secret changed
+// since the last push, the rotation generation is increased.
+func (r *ServiceClaimReconciler) recordSecretRotation(sclaim *primazaiov1alpha1.ServiceClaim, secret *corev1.Secret) {
+	checksum := secretChecksum(secret)
+	if sclaim.Status.SecretChecksum != "" && sclaim.Status.SecretChecksum != checksum {
+		sclaim.Status.SecretRotationGeneration++
+		msg := fmt.Sprintf("service endpoint definition secret updated, rotation generation %d", sclaim.Status.SecretRotationGeneration)
+		r.recordEvent(sclaim, corev1.EventTypeNormal, constants.SecretRotatedReason, msg)
+	}
+	sclaim.Status.SecretChecksum = checksum
+}
+
+// recordEvent records an event for the ServiceClaim, if the reconciler has
+// been built with an event recorder
+func (r *ServiceClaimReconciler) recordEvent(sclaim *primazaiov1alpha1.ServiceClaim, eventtype, reason, message string) {
+	if r.Recorder != nil {
+		r.Recorder.Event(sclaim, eventtype, reason, message)
+	}
+}
+
+// secretChecksum computes a checksum of the secret's data that does not
+// depend on the keys' order
+func secretChecksum(secret *corev1.Secret) string {
+	keys := make([]string, 0, len(secret.StringData))
+	for k := range secret.StringData {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%d:%s%d:%s", len(k), k, len(secret.StringData[k]), secret.StringData[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// failoverServiceClaim binds a Resolved ServiceClaim to the best matching
+// RegisteredService other than the unreachable one it is bound to.
+// The SED Secret and the ServiceBinding are then pushed again to the
+// application namespaces.
+func (r *ServiceClaimReconciler) failoverServiceClaim(
+	ctx context.Context,
+	sclaim primazaiov1alpha1.ServiceClaim,
+	unreachable primazaiov1alpha1.RegisteredService) error {
+	l := log.FromContext(ctx).WithValues("service-claim", sclaim.Name, "unreachable-registered-service", unreachable.Name)
+
+	var rsl primazaiov1alpha1.RegisteredServiceList
+	if err := r.List(ctx, &rsl, client.InNamespace(sclaim.Namespace)); err != nil {
+		l.Info("unable to retrieve RegisteredServiceList", "error", err)
+		return err
+	}
+	rss := slices.DeleteFunc(rsl.Items, func(rs primazaiov1alpha1.RegisteredService) bool {
+		return rs.UID == unreachable.UID
+	})
+
+	ranked, d, err := r.rankRegisteredServices(ctx, sclaim, rss)
+	if err != nil {
+		return err
+	}
+	setMatchedCondition(&sclaim, *d)
+	if len(ranked) == 0 {
+		msg := fmt.Sprintf("registered service %s is unreachable and no other one can be claimed: %s", unreachable.Name, d.Summary())
+		r.recordEvent(&sclaim, corev1.EventTypeWarning, constants.FailoverFailedReason, msg)
+		meta.SetStatusCondition(&sclaim.Status.Conditions, metav1.Condition{
+			LastTransitionTime: metav1.Now(),
+			Type:               string(primazaiov1alpha1.ServiceClaimConditionFailedOver),
+			Status:             metav1.ConditionFalse,
+			Reason:             constants.FailoverFailedReason,
+			Message:            msg,
+		})
+		return r.updateServiceClaimStatus(ctx, &sclaim)
+	}
+
+	rs := ranked[0].RegisteredService
+	secret, err := r.getServiceEndpointDefinition(ctx, sclaim, rs)
+	if err != nil {
+		l.Error(err, "error baking the ServiceEndpointDefinition", "registered-service", rs.Name)
+		return err
+	}
+
+	if err := r.claimRegisteredService(ctx, rs, sclaim.Status.ClaimID); err != nil {
+		l.Error(err, "error updating the RegisteredService", "registered-service", rs.Name)
+		return err
+	}
+
+	sclaim.Status.RegisteredService = &corev1.ObjectReference{
+		Name: rs.Name,
+		UID:  rs.UID,
+	}
+	sclaim.Status.ResolvedVersion = claim.ResolvedVersion(sclaim.Spec, rs)
+	if err := r.pushToClusterEnvironments(ctx, sclaim, secret); err != nil {
+		l.Error(err, "error pushing to cluster environments", "registered-service", rs.Name)
+		if err := r.releaseRegisteredService(ctx, rs, sclaim.Status.ClaimID); err != nil {
+			l.Error(err, "unable to update the RegisteredService", "registered-service", rs.Name)
+		}
+		return err
+	}
+
+	if err := r.releaseRegisteredService(ctx, unreachable, sclaim.Status.ClaimID); err != nil {
+		l.Error(err, "unable to release the unreachable RegisteredService")
+		return err
+	}
+	sclaim.Status.SecretChecksum = secretChecksum(secret)
+
+	msg := fmt.Sprintf("failed over from unreachable registered service %s to %s", unreachable.Name, rs.Name)
+	r.recordEvent(&sclaim, corev1.EventTypeNormal, constants.FailoverReason, msg)
+	meta.SetStatusCondition(&sclaim.Status.Conditions, metav1.Condition{
+		LastTransitionTime: metav1.Now(),
+		Type:               string(primazaiov1alpha1.ServiceClaimConditionFailedOver),
+		Status:             metav1.ConditionTrue,
+		Reason:             constants.FailoverReason,
+		Message:            msg,
+	})
+	meta.SetStatusCondition(&sclaim.Status.Conditions, metav1.Condition{
+		LastTransitionTime: metav1.Now(),
+		Type:               string(primazaiov1alpha1.ServiceClaimConditionRanked),
+		Status:             metav1.ConditionTrue,
+		Reason:             constants.CandidateSelectedReason,
+		Message:            claim.DescribeSelection(ranked),
+	})
+	return r.updateServiceClaimStatus(ctx, &sclaim)
+}
+
 func (r *ServiceClaimReconciler) updateServiceClaimStatus(ctx context.Context, sclaim *primazaiov1alpha1.ServiceClaim) error {
 	l := log.FromContext(ctx).WithValues("service-claim", sclaim.Name, "status", sclaim.Status)
 
@@ -414,89 +703,42 @@ func (r *ServiceClaimReconciler) processServiceClaim(
 		StringData: map[string]string{},
 	}
 
-	// count the number of secret data entries
-	count := 0
-	// loop over every RegisteredService
-	registeredServiceFound := false
-	var registeredService primazaiov1alpha1.RegisteredService
-
-	var env string
-	if sclaim.Spec.Target.ApplicationClusterContext != nil {
-		var err error
-		ce, err := r.getEnvironmentFromClusterEnvironment(ctx, sclaim.Namespace, sclaim.Spec.Target.ApplicationClusterContext.ClusterEnvironmentName)
-		if err != nil {
-			l.Error(err, "unable to get environment from cluster environment")
-			return err
-		}
-		env = ce.Spec.EnvironmentName
-	} else {
-		env = sclaim.Spec.Target.EnvironmentTag
+	if err := claim.ValidateVersionRanges(sclaim.Spec); err != nil {
+		return r.markServiceClaimInvalid(ctx, &sclaim, err)
 	}
 
-	for _, rs := range rsl.Items {
-		// Check if the registered Service is Available
-		if rs.Status.State != primazaiov1alpha1.RegisteredServiceStateAvailable {
-			continue
-		}
-
-		// Check if the ServiceClassIdentity given in ServiceClaim is a subset of
-		// ServiceClassIdentity given in the RegisteredService
-		if checkSCISubset(sclaim.Spec.ServiceClassIdentity, rs.Spec.ServiceClassIdentity) &&
-			(rs.Spec.Constraints == nil ||
-				envtag.Match(env, rs.Spec.Constraints.Environments)) {
-			registeredServiceFound = true
-			registeredService = rs
-			var err error
-			count, err = r.extractServiceEndpointDefinition(
-				ctx, sclaim.Namespace, rs, sclaim.Spec.ServiceEndpointDefinitionKeys, secret)
-			if err != nil {
-				l.Error(err, "unable to extract SED")
-				return err
-			}
-			break
-		}
+	ranked, d, err := r.rankRegisteredServices(ctx, sclaim, rsl.Items)
+	if err != nil {
+		return err
 	}
+	setMatchedCondition(&sclaim, *d)
+	if len(ranked) == 0 {
+		return r.markServiceClaimPending(ctx, &sclaim, "no registered service can be claimed: "+d.Summary())
+	}
+	registeredService := ranked[0].RegisteredService
+	l.Info("ranked eligible registered services", "selection", claim.DescribeSelection(ranked))
 
-	if !registeredServiceFound {
-		c := metav1.Condition{
-			LastTransitionTime: metav1.Now(),
-			Type:               string(primazaiov1alpha1.ServiceClaimConditionReady),
-			Status:             metav1.ConditionFalse,
-			Reason:             constants.NoMatchingServiceFoundReason,
-			Message:            "SCI is not matched",
-		}
-		meta.SetStatusCondition(&sclaim.Status.Conditions, c)
-
-		sclaim.Status.State = primazaiov1alpha1.ServiceClaimStatePending
-		if err := r.updateServiceClaimStatus(ctx, &sclaim); err != nil {
-			l.Error(err, "unable to update the ServiceClaim", "ServiceClaim", sclaim)
-			return err
-		}
-
-		return fmt.Errorf("SCI is not matched")
+	count, err := r.extractServiceEndpointDefinition(
+		ctx, sclaim.Namespace, registeredService, sclaim.Spec.ServiceEndpointDefinitionKeys, secret)
+	if err != nil {
+		l.Error(err, "unable to extract SED")
+		return err
 	}
 
 	// if the number of SED keys is more than the number of secret data entries
 	// that indicates one or more keys are missing
 	if len(sclaim.Spec.ServiceEndpointDefinitionKeys) > count {
-		c := metav1.Condition{
-			LastTransitionTime: metav1.Now(),
-			Type:               string(primazaiov1alpha1.ServiceClaimConditionReady),
-			Status:             metav1.ConditionFalse,
-			Reason:             constants.NoMatchingServiceFoundReason,
-			Message:            "key not available in the list of SEDs",
-		}
-		meta.SetStatusCondition(&sclaim.Status.Conditions, c)
-
-		sclaim.Status.State = primazaiov1alpha1.ServiceClaimStatePending
-		if err := r.updateServiceClaimStatus(ctx, &sclaim); err != nil {
-			l.Error(err, "unable to update the ServiceClaim", "ServiceClaim", sclaim)
-			return err
-		}
-
-		return fmt.Errorf("key not available in the list of SEDs")
+		return r.markServiceClaimPending(ctx, &sclaim, "key not available in the list of SEDs")
 	}
 
+	meta.SetStatusCondition(&sclaim.Status.Conditions, metav1.Condition{
+		LastTransitionTime: metav1.Now(),
+		Type:               string(primazaiov1alpha1.ServiceClaimConditionRanked),
+		Status:             metav1.ConditionTrue,
+		Reason:             constants.CandidateSelectedReason,
+		Message:            claim.DescribeSelection(ranked),
+	})
+
 	// ServiceClassIdentity values are going to override
 	// any values in the secret resource
 	for _, sci := range sclaim.Spec.ServiceClassIdentity {
@@ -508,13 +750,14 @@ func (r *ServiceClaimReconciler) processServiceClaim(
 		Name: registeredService.Name,
 		UID:  registeredService.UID,
 	}
+	sclaim.Status.ResolvedVersion = claim.ResolvedVersion(sclaim.Spec, registeredService)
 	if err := r.updateServiceClaimStatus(ctx, &sclaim); err != nil {
 		l.Error(err, "unable to update the ServiceClaim", "ServiceClaim", sclaim)
 		return err
 	}
 
-	// Update RegisteredService status to Claimed to avoid raise conditions
-	if err := r.changeServiceState(ctx, registeredService, primazaiov1alpha1.RegisteredServiceStateClaimed); err != nil {
+	// Bind the ServiceClaim to the RegisteredService to avoid raise conditions
+	if err := r.claimRegisteredService(ctx, registeredService, sclaim.Status.ClaimID); err != nil {
 		l.Error(err, "unable to update the RegisteredService", "RegisteredService", registeredService)
 		return err
 	}
@@ -524,15 +767,16 @@ func (r *ServiceClaimReconciler) processServiceClaim(
 		Name: registeredService.Name,
 		UID:  registeredService.UID,
 	}
-	err := r.pushToClusterEnvironments(ctx, sclaim, secret)
-	if err != nil {
+	sclaim.Status.ResolvedVersion = claim.ResolvedVersion(sclaim.Spec, registeredService)
+	if err := r.pushToClusterEnvironments(ctx, sclaim, secret); err != nil {
 		l.Error(err, "error pushing to cluster environments")
-		// Update RegisteredService status back to Available
-		if err := r.changeServiceState(ctx, registeredService, primazaiov1alpha1.RegisteredServiceStateAvailable); err != nil {
+		// Release the RegisteredService
+		if err := r.releaseRegisteredService(ctx, registeredService, sclaim.Status.ClaimID); err != nil {
 			l.Error(err, "unable to update the RegisteredService", "RegisteredService", registeredService)
 		}
 		return client.IgnoreNotFound(err)
 	}
+	sclaim.Status.SecretChecksum = secretChecksum(secret)
 
 	if err := r.updateServiceClaimStatus(ctx, &sclaim); err != nil {
 		l.Error(err, "unable to update the ServiceClaim", "ServiceClaim", sclaim)
@@ -542,6 +786,142 @@ func (r *ServiceClaimReconciler) processServiceClaim(
 	return nil
 }
 
+// rankRegisteredServices returns the RegisteredServices that can be bound to
+// the ServiceClaim, sorted from the best to the worst, together with the
+// diagnosis of the evaluated RegisteredServices.
+func (r *ServiceClaimReconciler) rankRegisteredServices(
+	ctx context.Context,
+	sclaim primazaiov1alpha1.ServiceClaim,
+	rss []primazaiov1alpha1.RegisteredService) ([]claim.Candidate, *claim.Diagnosis, error) {
+	l := log.FromContext(ctx)
+
+	env, err := r.getServiceClaimEnvironment(ctx, sclaim)
+	if err != nil {
+		l.Error(err, "unable to get environment from cluster environment")
+		return nil, nil, err
+	}
+
+	d := claim.Diagnose(sclaim, env, rss)
+	l.Info("evaluated registered services", "diagnosis", d.Summary())
+	if len(d.Eligibles) == 0 {
+		return nil, &d, nil
+	}
+
+	rc, err := r.buildRankingContext(ctx, sclaim)
+	if err != nil {
+		l.Error(err, "unable to build the ranking context")
+		return nil, nil, err
+	}
+	// prefer the highest versions among the ones satisfying the claim's version ranges
+	eligibles := claim.SelectHighestVersions(sclaim.Spec, d.Eligibles)
+	return r.ranker().Rank(*rc, eligibles), &d, nil
+}
+
+// setMatchedCondition reports in the ServiceClaim's conditions the
+// diagnosis of the RegisteredServices evaluated for the ServiceClaim
+func setMatchedCondition(sclaim *primazaiov1alpha1.ServiceClaim, d claim.Diagnosis) {
+	c := metav1.Condition{
+		LastTransitionTime: metav1.Now(),
+		Type:               string(primazaiov1alpha1.ServiceClaimConditionMatched),
+		Status:             metav1.ConditionTrue,
+		Reason:             constants.MatchingServicesFoundReason,
+		Message:            d.Report(maxDiagnosisReportLength),
+	}
+	if len(d.Eligibles) == 0 {
+		c.Status = metav1.ConditionFalse
+		c.Reason = constants.NoMatchingServiceFoundReason
+	}
+	meta.SetStatusCondition(&sclaim.Status.Conditions, c)
+}
+
+// markServiceClaimPending sets the ServiceClaim as Pending, reporting the
+// given message in the Ready condition, and returns an error with the same message
+func (r *ServiceClaimReconciler) markServiceClaimPending(ctx context.Context, sclaim *primazaiov1alpha1.ServiceClaim, message string) error {
+	l := log.FromContext(ctx)
+
+	c := metav1.Condition{
+		LastTransitionTime: metav1.Now(),
+		Type:               string(primazaiov1alpha1.ServiceClaimConditionReady),
+		Status:             metav1.ConditionFalse,
+		Reason:             constants.NoMatchingServiceFoundReason,
+		Message:            message,
+	}
+	meta.SetStatusCondition(&sclaim.Status.Conditions, c)
+
+	sclaim.Status.State = primazaiov1alpha1.ServiceClaimStatePending
+	if err := r.updateServiceClaimStatus(ctx, sclaim); err != nil {
+		l.Error(err, "unable to update the ServiceClaim", "ServiceClaim", sclaim)
+		return err
+	}
+
+	return errors.New(message)
+}
+
+// markServiceClaimInvalid sets the ServiceClaim as Invalid, reporting the
+// validation error in the Ready condition
+func (r *ServiceClaimReconciler) markServiceClaimInvalid(ctx context.Context, sclaim *primazaiov1alpha1.ServiceClaim, err error) error {
+	l := log.FromContext(ctx)
+
+	c := metav1.Condition{
+		LastTransitionTime: metav1.Now(),
+		Type:               string(primazaiov1alpha1.ServiceClaimConditionReady),
+		Status:             metav1.ConditionFalse,
+		Reason:             constants.ValidationErrorReason,
+		Message:            err.Error(),
+	}
+	meta.SetStatusCondition(&sclaim.Status.Conditions, c)
+
+	sclaim.Status.State = primazaiov1alpha1.ServiceClaimStateInvalid
+	if err := r.updateServiceClaimStatus(ctx, sclaim); err != nil {
+		l.Error(err, "unable to update the ServiceClaim", "ServiceClaim", sclaim)
+		return err
+	}
+
+	return nil
+}
+
+func (r *ServiceClaimReconciler) ranker() *claim.Ranker {
+	if r.Ranker == nil {
+		return claim.NewDefaultRanker()
+	}
+	return r.Ranker
+}
+
+// buildRankingContext collects the information needed to rank the
+// RegisteredServices eligible for the given ServiceClaim
+func (r *ServiceClaimReconciler) buildRankingContext(ctx context.Context, sclaim primazaiov1alpha1.ServiceClaim) (*claim.RankingContext, error) {
+	rc := claim.RankingContext{
+		ServiceClaim: sclaim,
+		ClaimsCount:  map[string]int{},
+	}
+
+	var scl primazaiov1alpha1.ServiceClaimList
+	if err := r.List(ctx, &scl, client.InNamespace(sclaim.Namespace)); err != nil {
+		return nil, err
+	}
+	for _, sc := range scl.Items {
+		if sc.Name != sclaim.Name && sc.Status.RegisteredService != nil {
+			rc.ClaimsCount[sc.Status.RegisteredService.Name]++
+		}
+	}
+
+	if acc := sclaim.Spec.Target.ApplicationClusterContext; acc != nil {
+		rc.LocalClusterEnvironments = []string{acc.ClusterEnvironmentName}
+		return &rc, nil
+	}
+
+	var cel primazaiov1alpha1.ClusterEnvironmentList
+	if err := r.List(ctx, &cel, client.InNamespace(sclaim.Namespace)); err != nil {
+		return nil, err
+	}
+	for _, ce := range cel.Items {
+		if ce.Spec.EnvironmentName == sclaim.Spec.Target.EnvironmentTag {
+			rc.LocalClusterEnvironments = append(rc.LocalClusterEnvironments, ce.Name)
+		}
+	}
+	return &rc, nil
+}
+
 func (r *ServiceClaimReconciler) updateRemoteServiceClaimStatusIfNeeded(
 	ctx context.Context,
 	sclaim primazaiov1alpha1.ServiceClaim,
@@ -589,12 +969,18 @@ func (r *ServiceClaimReconciler) updateRemoteServiceClaimStatusIfNeeded(
 	l.Info("retrieved remote service claim", "status", rsc.Status)
 
 	l = l.WithValues("status", sclaim.Status)
+	conditionsChanged := mergeControlPlaneConditions(&rsc.Status.Conditions, sclaim.Status.Conditions)
 	if rsc.Status.RegisteredService != sclaim.Status.RegisteredService ||
 		rsc.Status.State != sclaim.Status.State ||
-		!reflect.DeepEqual(rsc.Status.Conditions, sclaim.Status.Conditions) {
+		rsc.Status.ResolvedVersion != sclaim.Status.ResolvedVersion ||
+		rsc.Status.SecretRotationGeneration != sclaim.Status.SecretRotationGeneration ||
+		!reflect.DeepEqual(rsc.Status.DryRun, sclaim.Status.DryRun) ||
+		conditionsChanged {
 		rsc.Status.RegisteredService = sclaim.Status.RegisteredService
 		rsc.Status.State = sclaim.Status.State
-		rsc.Status.Conditions = sclaim.Status.Conditions
+		rsc.Status.ResolvedVersion = sclaim.Status.ResolvedVersion
+		rsc.Status.SecretRotationGeneration = sclaim.Status.SecretRotationGeneration
+		rsc.Status.DryRun = sclaim.Status.DryRun
 		if err := cli.Status().Update(ctx, &rsc); err != nil {
 			l.Error(err, "error updating serviceclaim status")
 			return fmt.Errorf("error updating ServiceClaim from application namespace %s of cluster environment %s: %w", ans, ce.Name, err)
@@ -607,6 +993,46 @@ func (r *ServiceClaimReconciler) updateRemoteServiceClaimStatusIfNeeded(
 	return nil
 }
 
+// mergeControlPlaneConditions sets the control plane's ServiceClaim
+// conditions into the conditions of the ServiceClaim in the application
+// namespace, leaving the ones owned by the application agent untouched.
+// It returns true if any condition changed.
+func mergeControlPlaneConditions(conditions *[]metav1.Condition, controlPlane []metav1.Condition) bool {
+	removed := []string{}
+	for _, c := range *conditions {
+		if !isApplicationAgentCondition(c.Type) && meta.FindStatusCondition(controlPlane, c.Type) == nil {
+			removed = append(removed, c.Type)
+		}
+	}
+	for _, t := range removed {
+		meta.RemoveStatusCondition(conditions, t)
+	}
+
+	changed := len(removed) > 0
+
+	for _, c := range controlPlane {
+		if isApplicationAgentCondition(c.Type) {
+			continue
+		}
+		current := meta.FindStatusCondition(*conditions, c.Type)
+		if current == nil ||
+			current.Status != c.Status ||
+			current.Reason != c.Reason ||
+			current.Message != c.Message ||
+			current.ObservedGeneration != c.ObservedGeneration {
+			meta.SetStatusCondition(conditions, c)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// isApplicationAgentCondition returns true if the ServiceClaim condition is
+// set by the application agent in the application namespace
+func isApplicationAgentCondition(conditionType string) bool {
+	return conditionType == string(primazaiov1alpha1.ServiceClaimConditionCataloged)
+}
+
 func (r *ServiceClaimReconciler) pushToClusterEnvironments(
 	ctx context.Context,
 	sclaim primazaiov1alpha1.ServiceClaim,
@@ -713,7 +1139,16 @@ func (r *ServiceClaimReconciler) DeleteServiceBindingsAndSecret(
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ServiceClaimReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	genPred := predicate.GenerationChangedPredicate{}
+	if err := mgr.GetFieldIndexer().IndexField(
+		context.Background(),
+		&primazaiov1alpha1.RegisteredService{},
+		RegisteredServiceSecretRefIndex,
+		registeredServiceSecretRefs); err != nil {
+		return err
+	}
+
+	// annotation changes are watched too, so that claims can be moved out of dry run
+	genPred := predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{})
 	reconcileOnRegisteredServiceUpdate := func(ctx context.Context, a client.Object) []reconcile.Request {
 		l := log.FromContext(ctx)
 		rs, ok := a.(*v1alpha1.RegisteredService)
@@ -721,32 +1156,82 @@ func (r *ServiceClaimReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			l.Info("error parsing object to RegisteredService when mapping to ServiceClaim reconciliation trigger", "object", a)
 			return []reconcile.Request{}
 		}
-		if rs.Status.State != `Claimed` {
-			l.Info("Registered service is unclaimed, no service claim to reconcile", "registered-service", rs.Name)
-			return []reconcile.Request{}
-		}
-		serviceclaims := &v1alpha1.ServiceClaimList{}
-		opts := &client.ListOptions{}
-		if err := r.List(ctx, serviceclaims, opts); err != nil {
-			l.Error(err,
-				"unable to list the ServiceClaims and reconcile for Registered Service Updates",
-				"RegisteredService", rs.Name)
-			return []reconcile.Request{}
-		}
-		for _, sc := range serviceclaims.Items {
-			if sc.Status.State == primazaiov1alpha1.ServiceClaimStateResolved &&
-				sc.Status.RegisteredService != nil && sc.Status.RegisteredService.UID == rs.UID {
-				return []reconcile.Request{{NamespacedName: types.NamespacedName{
-					Namespace: sc.Namespace,
-					Name:      sc.Name,
-				}}}
-			}
-		}
-		return []reconcile.Request{}
+		return r.resolvedServiceClaimsRequests(ctx, *rs)
 	}
+	// RegisteredService status changes are watched too, so that claims bound
+	// to services becoming Unreachable can fail over.
+	// Secrets are watched so that rotated values are pushed again to the
+	// application namespaces.
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&primazaiov1alpha1.ServiceClaim{}).
+		For(&primazaiov1alpha1.ServiceClaim{}, builder.WithPredicates(genPred)).
 		Watches(&primazaiov1alpha1.RegisteredService{}, handler.EnqueueRequestsFromMapFunc(reconcileOnRegisteredServiceUpdate)).
-		WithEventFilter(genPred).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.reconcileOnSecretUpdate)).
 		Complete(r)
 }
+
+// RegisteredServiceSecretRefIndex indexes RegisteredServices by the name of
+// the secrets referenced in their ServiceEndpointDefinition
+const RegisteredServiceSecretRefIndex = "spec.serviceEndpointDefinition.valueFromSecret.name"
+
+func registeredServiceSecretRefs(o client.Object) []string {
+	rs, ok := o.(*primazaiov1alpha1.RegisteredService)
+	if !ok {
+		return nil
+	}
+
+	names := []string{}
+	for _, item := range rs.Spec.ServiceEndpointDefinition {
+		if item.ValueFromSecret != nil && !slices.Contains(names, item.ValueFromSecret.Name) {
+			names = append(names, item.ValueFromSecret.Name)
+		}
+	}
+	return names
+}
+
+// reconcileOnSecretUpdate maps a secret to the Resolved ServiceClaims bound to
+// the RegisteredServices referencing it
+func (r *ServiceClaimReconciler) reconcileOnSecretUpdate(ctx context.Context, a client.Object) []reconcile.Request {
+	l := log.FromContext(ctx).WithValues("secret", a.GetName())
+
+	var rsl primazaiov1alpha1.RegisteredServiceList
+	if err := r.List(ctx, &rsl,
+		client.InNamespace(a.GetNamespace()),
+		client.MatchingFields{RegisteredServiceSecretRefIndex: a.GetName()}); err != nil {
+		l.Error(err, "unable to list the RegisteredServices referencing the secret")
+		return []reconcile.Request{}
+	}
+
+	requests := []reconcile.Request{}
+	for _, rs := range rsl.Items {
+		l.Info("secret referenced by registered service updated", "registered-service", rs.Name)
+		requests = append(requests, r.resolvedServiceClaimsRequests(ctx, rs)...)
+	}
+	return requests
+}
+
+// resolvedServiceClaimsRequests returns the reconcile requests for the
+// Resolved ServiceClaims bound to the RegisteredService.  The RegisteredService's
+// state and claims are not checked: services claimed before claims were
+// tracked may have become Unreachable, and their claims need to fail over.
+func (r *ServiceClaimReconciler) resolvedServiceClaimsRequests(ctx context.Context, rs primazaiov1alpha1.RegisteredService) []reconcile.Request {
+	l := log.FromContext(ctx)
+	serviceclaims := &v1alpha1.ServiceClaimList{}
+	opts := &client.ListOptions{}
+	if err := r.List(ctx, serviceclaims, opts); err != nil {
+		l.Error(err,
+			"unable to list the ServiceClaims and reconcile for Registered Service Updates",
+			"RegisteredService", rs.Name)
+		return []reconcile.Request{}
+	}
+	requests := []reconcile.Request{}
+	for _, sc := range serviceclaims.Items {
+		if sc.Status.State == primazaiov1alpha1.ServiceClaimStateResolved &&
+			sc.Status.RegisteredService != nil && sc.Status.RegisteredService.UID == rs.UID {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+				Namespace: sc.Namespace,
+				Name:      sc.Name,
+			}})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/primaza/claim"
+	"github.com/primaza/primaza/pkg/primaza/constants"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Service Claim reconciler tests", func() {
+	Describe("Dry run tests", func() {
+		var (
+			client         client.Client
+			namespace      string
+			scController   ServiceClaimReconciler
+			rs             v1alpha1.RegisteredService
+			sclaim         v1alpha1.ServiceClaim
+			ctx            context.Context
+			namespacedName types.NamespacedName
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			namespace = "primaza-system"
+
+			rs = v1alpha1.RegisteredService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "postgres",
+					Namespace: namespace,
+					UID:       "1d9c3ab8-9a63-4ff0-a3a8-d0c3e9b5b2a7",
+				},
+				Spec: v1alpha1.RegisteredServiceSpec{
+					ServiceClassIdentity: []v1alpha1.ServiceClassIdentityItem{
+						{Name: "engine", Value: "postgres"},
+						{Name: "version", Value: "15.2"},
+					},
+					ServiceEndpointDefinition: []v1alpha1.ServiceEndpointDefinitionItem{
+						{Name: "host", Value: "postgres.svc"},
+						{Name: "password", ValueFromSecret: &v1alpha1.ServiceEndpointDefinitionSecretRef{Name: "postgres", Key: "password"}},
+					},
+				},
+				Status: v1alpha1.RegisteredServiceStatus{State: v1alpha1.RegisteredServiceStateAvailable},
+			}
+			secret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: namespace},
+				Data:       map[string][]byte{"password": []byte("secret")},
+			}
+			sclaim = v1alpha1.ServiceClaim{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "claim",
+					Namespace:   namespace,
+					Annotations: map[string]string{constants.DryRunAnnotation: "true"},
+				},
+				Spec: v1alpha1.ServiceClaimSpec{
+					ServiceClassIdentity: []v1alpha1.ServiceClassIdentityItem{{Name: "engine", Value: "postgres"}},
+					ServiceClassIdentityExpressions: []v1alpha1.ServiceClassIdentityRequirement{
+						{Name: "version", Operator: v1alpha1.ServiceClassIdentityOperatorVersionRange, Values: []string{">= 14"}},
+					},
+					ServiceEndpointDefinitionKeys: []string{"host", "password"},
+					Target:                        &v1alpha1.ServiceClaimTarget{EnvironmentTag: "dev"},
+				},
+			}
+			namespacedName = types.NamespacedName{Namespace: namespace, Name: sclaim.Name}
+
+			scheme := runtime.NewScheme()
+			Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+			client = fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(&rs, &secret, &sclaim).
+				WithStatusSubresource(&rs, &sclaim).
+				Build()
+
+			scController = ServiceClaimReconciler{
+				Client: client,
+				Scheme: client.Scheme(),
+				Ranker: claim.NewDefaultRanker(),
+			}
+		})
+
+		It("should report the selected service without claiming it", func() {
+			_, err := scController.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.Get(ctx, namespacedName, &sclaim)).To(Succeed())
+			Expect(sclaim.Status.State).To(Equal(v1alpha1.ServiceClaimStatePending))
+			Expect(sclaim.Status.RegisteredService).To(BeNil())
+			Expect(sclaim.Finalizers).To(BeEmpty())
+			Expect(sclaim.Status.DryRun).NotTo(BeNil())
+			Expect(sclaim.Status.DryRun.RegisteredService.Name).To(Equal(rs.Name))
+			Expect(sclaim.Status.DryRun.ResolvedVersion).To(Equal("15.2"))
+			Expect(sclaim.Status.DryRun.SecretKeys).To(Equal([]string{"engine", "host", "password"}))
+
+			c := meta.FindStatusCondition(sclaim.Status.Conditions, string(v1alpha1.ServiceClaimConditionReady))
+			Expect(c).NotTo(BeNil())
+			Expect(c.Reason).To(Equal(constants.DryRunReason))
+
+			Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: rs.Name}, &rs)).To(Succeed())
+			Expect(rs.Status.State).To(Equal(v1alpha1.RegisteredServiceStateAvailable))
+			Expect(rs.Status.Claims).To(BeEmpty())
+		})
+
+		It("should report why no service can be selected", func() {
+			sclaim.Spec.ServiceEndpointDefinitionKeys = []string{"host", "username"}
+			Expect(client.Update(ctx, &sclaim)).To(Succeed())
+
+			_, err := scController.Reconcile(ctx, ctrl.Request{NamespacedName: namespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(client.Get(ctx, namespacedName, &sclaim)).To(Succeed())
+			Expect(sclaim.Status.DryRun.RegisteredService).To(BeNil())
+
+			c := meta.FindStatusCondition(sclaim.Status.Conditions, string(v1alpha1.ServiceClaimConditionMatched))
+			Expect(c).NotTo(BeNil())
+			Expect(c.Status).To(Equal(metav1.ConditionFalse))
+			Expect(c.Message).To(ContainSubstring("postgres: MissingKeys (username)"))
+		})
+	})
+
+	Describe("Secret rotation tests", func() {
+		var (
+			client       client.Client
+			namespace    string
+			scController ServiceClaimReconciler
+			rs           v1alpha1.RegisteredService
+			secret       corev1.Secret
+			ctx          context.Context
+		)
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			namespace = "primaza-system"
+
+			rs = v1alpha1.RegisteredService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "postgres",
+					Namespace: namespace,
+					UID:       "1d9c3ab8-9a63-4ff0-a3a8-d0c3e9b5b2a7",
+				},
+				Spec: v1alpha1.RegisteredServiceSpec{
+					ServiceEndpointDefinition: []v1alpha1.ServiceEndpointDefinitionItem{
+						{Name: "host", Value: "postgres.svc"},
+						{Name: "user", ValueFromSecret: &v1alpha1.ServiceEndpointDefinitionSecretRef{Name: "postgres", Key: "user"}},
+						{Name: "password", ValueFromSecret: &v1alpha1.ServiceEndpointDefinitionSecretRef{Name: "postgres", Key: "password"}},
+					},
+				},
+				Status: v1alpha1.RegisteredServiceStatus{
+					State:  v1alpha1.RegisteredServiceStateClaimed,
+					Claims: []string{"claim-id"},
+				},
+			}
+			secret = corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: namespace},
+			}
+			resolved := v1alpha1.ServiceClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "resolved", Namespace: namespace},
+				Status: v1alpha1.ServiceClaimStatus{
+					State:             v1alpha1.ServiceClaimStateResolved,
+					RegisteredService: &corev1.ObjectReference{Name: rs.Name, UID: rs.UID},
+				},
+			}
+			pending := v1alpha1.ServiceClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: namespace},
+				Status:     v1alpha1.ServiceClaimStatus{State: v1alpha1.ServiceClaimStatePending},
+			}
+			other := v1alpha1.RegisteredService{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: namespace, UID: "3f8e2a0c-1b7d-4c52-9e61-0a4b8d2f7c15"},
+				Status: v1alpha1.RegisteredServiceStatus{
+					State:  v1alpha1.RegisteredServiceStateClaimed,
+					Claims: []string{"other-id"},
+				},
+			}
+
+			scheme := runtime.NewScheme()
+			Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+			client = fake.NewClientBuilder().
+				WithScheme(scheme).
+				WithObjects(&rs, &other, &secret, &resolved, &pending).
+				WithIndex(&v1alpha1.RegisteredService{}, RegisteredServiceSecretRefIndex, registeredServiceSecretRefs).
+				Build()
+
+			scController = ServiceClaimReconciler{
+				Client: client,
+				Scheme: client.Scheme(),
+			}
+		})
+
+		It("should index registered services by referenced secrets", func() {
+			Expect(registeredServiceSecretRefs(&rs)).To(Equal([]string{"postgres"}))
+		})
+
+		It("should reconcile resolved claims bound to services referencing an updated secret", func() {
+			requests := scController.reconcileOnSecretUpdate(ctx, &secret)
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].Name).To(Equal("resolved"))
+
+			secret.Name = "unreferenced"
+			Expect(scController.reconcileOnSecretUpdate(ctx, &secret)).To(BeEmpty())
+		})
+
+		It("should increase the rotation generation when the secret changes", func() {
+			sclaim := v1alpha1.ServiceClaim{}
+			baked := corev1.Secret{StringData: map[string]string{"host": "postgres.svc", "password": "old"}}
+
+			scController.recordSecretRotation(&sclaim, &baked)
+			Expect(sclaim.Status.SecretChecksum).NotTo(BeEmpty())
+			Expect(sclaim.Status.SecretRotationGeneration).To(BeZero())
+
+			scController.recordSecretRotation(&sclaim, &baked)
+			Expect(sclaim.Status.SecretRotationGeneration).To(BeZero())
+
+			baked.StringData["password"] = "new"
+			scController.recordSecretRotation(&sclaim, &baked)
+			Expect(sclaim.Status.SecretRotationGeneration).To(Equal(int64(1)))
+		})
+	})
+
+	Describe("Application namespace status tests", func() {
+		It("should merge the control plane conditions without overwriting the agent ones", func() {
+			cataloged := metav1.Condition{
+				Type:               string(v1alpha1.ServiceClaimConditionCataloged),
+				Status:             metav1.ConditionTrue,
+				Reason:             "MatchingServicesFound",
+				LastTransitionTime: metav1.Now(),
+			}
+			conditions := []metav1.Condition{
+				cataloged,
+				{Type: "Stale", Status: metav1.ConditionTrue, Reason: "Stale"},
+			}
+			controlPlane := []metav1.Condition{
+				{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Resolved"},
+			}
+
+			Expect(mergeControlPlaneConditions(&conditions, controlPlane)).To(BeTrue())
+			Expect(conditions).To(HaveLen(2))
+			Expect(meta.FindStatusCondition(conditions, "Stale")).To(BeNil())
+			Expect(meta.IsStatusConditionTrue(conditions, "Ready")).To(BeTrue())
+			Expect(*meta.FindStatusCondition(conditions, cataloged.Type)).To(Equal(cataloged))
+
+			Expect(mergeControlPlaneConditions(&conditions, controlPlane)).To(BeFalse())
+		})
+	})
+})
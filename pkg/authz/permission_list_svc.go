@@ -25,6 +25,14 @@ var SvcPermissionList = []Permission{
 		Name:          "primaza:svc:manager",
 		Verbs:         []string{"create", "delete", "update", "get", "list", "watch"},
 	},
+	{
+		APIGroups:     []string{""},
+		Resources:     []string{"configmaps", "services"},
+		ResourceNames: []string{},
+		Namespace:     "system",
+		Name:          "primaza:svc:manager",
+		Verbs:         []string{"get", "list", "watch"},
+	},
 	{
 		APIGroups:     []string{"apps"},
 		Resources:     []string{"deployments"},
@@ -46,6 +46,17 @@ func TestResourcePermissions(ctx context.Context, cfg *rest.Config, namespaces [
 	return checkPermissions(ctx, c, namespaces, permissions), nil
 }
 
+// TestClusterResourcePermissions checks the permissions on cluster-scoped
+// resources, or on resources in all the namespaces
+func TestClusterResourcePermissions(ctx context.Context, cfg *rest.Config, permissions []ResourcePermissions) (NamespacedPermissionsReport, error) {
+	c, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return NamespacedPermissionsReport{}, fmt.Errorf("error creating the client: %w", err)
+	}
+
+	return checkPermissionsInNamespace(ctx, c, metav1.NamespaceAll, permissions), nil
+}
+
 func checkPermissions(ctx context.Context, c *kubernetes.Clientset, namespaces []string, permissions []ResourcePermissions) map[string]NamespacedPermissionsReport {
 	rr := map[string]NamespacedPermissionsReport{}
 	for _, ns := range namespaces {
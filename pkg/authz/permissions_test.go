@@ -26,3 +26,17 @@ func TestCreateUnwantedPermissions(t *testing.T) {
 		t.Errorf("Wrong output: %v", unwantedPermissions)
 	}
 }
+
+func TestNamespacedPermissionString(t *testing.T) {
+	pp := map[NamespacedPermission]string{
+		{Verb: "get", Resource: "secrets", Version: "v1", Namespace: "services"}:                 "get secrets./v1 in services",
+		{Verb: "list", Resource: "buckets", Group: "s3.aws", Version: "v1"}:                      "list buckets.s3.aws/v1 cluster-wide",
+		{Verb: "get", Resource: "buckets", Group: "s3.aws", Version: "v1", Name: "orders-files"}: "get buckets.s3.aws/v1 orders-files cluster-wide",
+	}
+
+	for p, o := range pp {
+		if p.String() != o {
+			t.Errorf("Wrong output: expected %s, got %s", o, p.String())
+		}
+	}
+}
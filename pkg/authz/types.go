@@ -56,13 +56,18 @@ type NamespacedPermission struct {
 }
 
 func (p NamespacedPermission) String() string {
+	scope := "in " + p.Namespace
+	if p.Namespace == "" {
+		scope = "cluster-wide"
+	}
+
 	if p.Name == "" {
-		return fmt.Sprintf("%s %s.%s/%s in %s",
-			p.Verb, p.Resource, p.Group, p.Version, p.Namespace)
+		return fmt.Sprintf("%s %s.%s/%s %s",
+			p.Verb, p.Resource, p.Group, p.Version, scope)
 	}
 
-	return fmt.Sprintf("%s %s.%s/%s %s in %s",
-		p.Verb, p.Resource, p.Group, p.Version, p.Name, p.Namespace)
+	return fmt.Sprintf("%s %s.%s/%s %s %s",
+		p.Verb, p.Resource, p.Group, p.Version, p.Name, scope)
 }
 
 func (np *NamespacedPermission) selfSubjectAccessReview() authorizationv1.SelfSubjectAccessReview {
@@ -0,0 +1,89 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package celexpr
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+)
+
+// SelfVariable is the name of the variable the evaluated resource is bound to
+const SelfVariable = "self"
+
+// costLimit bounds the evaluation cost of an expression, as done by
+// Kubernetes for CEL validation rules
+const costLimit = 1000000
+
+var env, envErr = cel.NewEnv(
+	cel.Variable(SelfVariable, cel.DynType),
+	ext.Strings(),
+)
+
+// Compile parses and type-checks the expression
+func Compile(expression string) (cel.Program, error) {
+	if envErr != nil {
+		return nil, envErr
+	}
+
+	ast, iss := env.Compile(expression)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+	return env.Program(ast, cel.CostLimit(costLimit))
+}
+
+// Evaluate runs the program against the resource.  The program must evaluate
+// to a scalar value, that is returned as a string.
+func Evaluate(program cel.Program, resource map[string]interface{}) (*string, error) {
+	out, _, err := program.Eval(map[string]interface{}{SelfVariable: resource})
+	if err != nil {
+		return nil, err
+	}
+
+	switch out.Type() {
+	case types.StringType, types.IntType, types.UintType, types.DoubleType, types.BoolType:
+		value := fmt.Sprintf("%v", out.Value())
+		return &value, nil
+	default:
+		return nil, fmt.Errorf("expression must evaluate to a scalar value, got %s", out.Type().TypeName())
+	}
+}
+
+// EvaluateBool runs the program against the resource.  The program must
+// evaluate to a boolean value.
+func EvaluateBool(program cel.Program, resource map[string]interface{}) (bool, error) {
+	out, _, err := program.Eval(map[string]interface{}{SelfVariable: resource})
+	if err != nil {
+		return false, err
+	}
+
+	if out.Type() != types.BoolType {
+		return false, fmt.Errorf("expression must evaluate to a boolean value, got %s", out.Type().TypeName())
+	}
+	return out.Value().(bool), nil
+}
+
+// EvaluateExpression compiles the expression and runs it against the resource
+func EvaluateExpression(expression string, resource map[string]interface{}) (*string, error) {
+	program, err := Compile(expression)
+	if err != nil {
+		return nil, err
+	}
+	return Evaluate(program, resource)
+}
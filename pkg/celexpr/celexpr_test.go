@@ -0,0 +1,84 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package celexpr_test
+
+import (
+	"testing"
+
+	"github.com/primaza/primaza/pkg/celexpr"
+)
+
+func Test_EvaluateExpression(t *testing.T) {
+	resource := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "orders-db"},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "sidecar", "ports": []interface{}{map[string]interface{}{"containerPort": int64(8080)}}},
+				map[string]interface{}{"name": "db", "ports": []interface{}{map[string]interface{}{"containerPort": int64(5432)}}},
+			},
+			"tls": true,
+		},
+		"status": map[string]interface{}{
+			"endpoints": []interface{}{
+				map[string]interface{}{"host": "10.0.0.1", "ready": false},
+				map[string]interface{}{"host": "10.0.0.2", "ready": true},
+			},
+		},
+	}
+
+	type test struct {
+		expression string
+		want       string
+		wantErr    bool
+	}
+
+	tt := []test{
+		{expression: "self.metadata.name", want: "orders-db"},
+		{expression: "self.spec.containers.filter(c, c.name == 'db')[0].ports[0].containerPort", want: "5432"},
+		{expression: "self.status.endpoints.filter(e, e.ready)[0].host", want: "10.0.0.2"},
+		{expression: "has(self.spec.port) ? self.spec.port : 5432", want: "5432"},
+		{expression: "self.spec.tls", want: "true"},
+		{expression: "self.metadata.name.upperAscii()", want: "ORDERS-DB"},
+		{expression: "self.spec.containers", wantErr: true},
+		{expression: "self.spec.port", wantErr: true},
+		{expression: "self.metadata.name +", wantErr: true},
+	}
+
+	for _, tc := range tt {
+		v, err := celexpr.EvaluateExpression(tc.expression, resource)
+		switch {
+		case tc.wantErr && err == nil:
+			t.Errorf("expected error evaluating %s, got %s", tc.expression, *v)
+		case !tc.wantErr && err != nil:
+			t.Errorf("unexpected error evaluating %s: %v", tc.expression, err)
+		case !tc.wantErr && *v != tc.want:
+			t.Errorf("expected %s evaluating %s, got %s", tc.want, tc.expression, *v)
+		}
+	}
+}
+
+func Test_Compile(t *testing.T) {
+	if _, err := celexpr.Compile("self.spec.host"); err != nil {
+		t.Errorf("unexpected error compiling valid expression: %v", err)
+	}
+	if _, err := celexpr.Compile("undefined.spec.host"); err == nil {
+		t.Error("expected error compiling expression referencing undeclared variables")
+	}
+	if _, err := celexpr.Compile("self.spec.host.unknownFunction()"); err == nil {
+		t.Error("expected error compiling expression calling undeclared functions")
+	}
+}
@@ -16,7 +16,10 @@ limitations under the License.
 
 package envtag
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
 const NegativeConstraintSymbol = "!"
 
@@ -63,3 +66,18 @@ func match(environment, constraint string) matchResult {
 	}
 	return unmatched
 }
+
+// ValidateConstraint checks that a constraint is either an environment name
+// or a negated environment name
+func ValidateConstraint(constraint string) error {
+	environment := strings.TrimPrefix(constraint, NegativeConstraintSymbol)
+	switch {
+	case environment == "":
+		return fmt.Errorf("constraint %q does not define an environment", constraint)
+	case strings.HasPrefix(environment, NegativeConstraintSymbol):
+		return fmt.Errorf("constraint %q is negated more than once", constraint)
+	case strings.ContainsAny(environment, " \t\n"):
+		return fmt.Errorf("constraint %q contains whitespaces", constraint)
+	}
+	return nil
+}
@@ -50,3 +50,25 @@ func Test_MatchEmptyContainsts(t *testing.T) {
 		}
 	}
 }
+
+func Test_ValidateConstraint(t *testing.T) {
+	type test struct {
+		constraint string
+		valid      bool
+	}
+
+	tt := []test{
+		{constraint: "prod", valid: true},
+		{constraint: "!prod", valid: true},
+		{constraint: "", valid: false},
+		{constraint: "!", valid: false},
+		{constraint: "!!prod", valid: false},
+		{constraint: "pro d", valid: false},
+	}
+
+	for _, te := range tt {
+		if err := envtag.ValidateConstraint(te.constraint); (err == nil) != te.valid {
+			t.Errorf("constraint %q: expected valid %v, got error %v", te.constraint, te.valid, err)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package annotatedservice
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/primaza/constants"
+)
+
+// secretRefPrefix marks Service Endpoint Definition annotations whose value
+// is read from a secret, in the form `secret:<secret-name>/<secret-key>`
+const secretRefPrefix = "secret:"
+
+// IsAnnotated returns true if the object declares a ServiceClassIdentity
+func IsAnnotated(obj metav1.Object) bool {
+	_, ok := obj.GetAnnotations()[constants.ServiceClassIdentityAnnotation]
+	return ok
+}
+
+// ServiceClass builds the ServiceClass describing the annotated object, so
+// that it can be registered as any other service resource.  The
+// ServiceClass shares the object's name and namespace.
+func ServiceClass(obj metav1.Object, apiVersion string, kind string) (*v1alpha1.ServiceClass, error) {
+	annotations := obj.GetAnnotations()
+	identity, err := ParseServiceClassIdentity(annotations[constants.ServiceClassIdentityAnnotation])
+	if err != nil {
+		return nil, err
+	}
+
+	mappings, err := ParseMappings(annotations)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1alpha1.ServiceClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      obj.GetName(),
+			Namespace: obj.GetNamespace(),
+		},
+		Spec: v1alpha1.ServiceClassSpec{
+			Resource: v1alpha1.ServiceClassResource{
+				APIVersion:                        apiVersion,
+				Kind:                              kind,
+				ServiceEndpointDefinitionMappings: *mappings,
+			},
+			ServiceClassIdentity: identity,
+		},
+	}, nil
+}
+
+// ParseServiceClassIdentity parses a comma-separated list of key=value pairs
+func ParseServiceClassIdentity(value string) ([]v1alpha1.ServiceClassIdentityItem, error) {
+	identity := []v1alpha1.ServiceClassIdentityItem{}
+	for _, item := range strings.Split(value, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(item), "=")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if !ok || k == "" || v == "" {
+			return nil, fmt.Errorf("invalid service class identity item '%s': expected key=value", item)
+		}
+		identity = append(identity, v1alpha1.ServiceClassIdentityItem{Name: k, Value: v})
+	}
+	return identity, nil
+}
+
+// ParseMappings builds the Service Endpoint Definition mappings from the
+// annotations prefixed with `primaza.io/sed-`.  Values in the form
+// `secret:<secret-name>/<secret-key>` are read from a secret in the
+// object's namespace, all other values are copied as they are.
+func ParseMappings(annotations map[string]string) (*v1alpha1.ServiceEndpointDefinitionMappings, error) {
+	keys := []string{}
+	for a := range annotations {
+		if strings.HasPrefix(a, constants.SEDAnnotationPrefix) {
+			keys = append(keys, a)
+		}
+	}
+	sort.Strings(keys)
+
+	mappings := v1alpha1.ServiceEndpointDefinitionMappings{}
+	for _, a := range keys {
+		key := strings.TrimPrefix(a, constants.SEDAnnotationPrefix)
+		if key == "" {
+			return nil, fmt.Errorf("invalid annotation '%s': empty service endpoint definition key", a)
+		}
+
+		value := annotations[a]
+		if !strings.HasPrefix(value, secretRefPrefix) {
+			mappings.ResourceFields = append(mappings.ResourceFields, v1alpha1.ServiceClassResourceFieldMapping{
+				Name:       key,
+				Expression: fmt.Sprintf("self.metadata.annotations['%s']", a),
+			})
+			continue
+		}
+
+		name, secretKey, ok := strings.Cut(strings.TrimPrefix(value, secretRefPrefix), "/")
+		if !ok || name == "" || secretKey == "" {
+			return nil, fmt.Errorf("invalid annotation '%s': expected secret:<secret-name>/<secret-key>", a)
+		}
+		mappings.SecretRefFields = append(mappings.SecretRefFields, v1alpha1.ServiceClassSecretRefFieldMapping{
+			Name:       key,
+			SecretName: v1alpha1.FieldMapping{Constant: &name},
+			SecretKey:  v1alpha1.FieldMapping{Constant: &secretKey},
+		})
+	}
+	return &mappings, nil
+}
+
+// ReferencesSecret returns true if any of the object's Service Endpoint
+// Definition annotations reads a value from the given secret
+func ReferencesSecret(obj metav1.Object, secretName string) bool {
+	for a, v := range obj.GetAnnotations() {
+		if strings.HasPrefix(a, constants.SEDAnnotationPrefix) &&
+			strings.HasPrefix(v, secretRefPrefix+secretName+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package annotatedservice_test
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/primaza/annotatedservice"
+)
+
+func Test_ParseServiceClassIdentity(t *testing.T) {
+	type test struct {
+		name     string
+		value    string
+		expected []v1alpha1.ServiceClassIdentityItem
+		wantErr  bool
+	}
+
+	tt := []test{
+		{
+			name:  "single item",
+			value: "type=psql",
+			expected: []v1alpha1.ServiceClassIdentityItem{
+				{Name: "type", Value: "psql"},
+			},
+		},
+		{
+			name:  "multiple items with spaces",
+			value: "type=psql, provider = vm",
+			expected: []v1alpha1.ServiceClassIdentityItem{
+				{Name: "type", Value: "psql"},
+				{Name: "provider", Value: "vm"},
+			},
+		},
+		{name: "empty", value: "", wantErr: true},
+		{name: "missing value", value: "type=psql,provider", wantErr: true},
+		{name: "empty key", value: "=psql", wantErr: true},
+	}
+
+	for _, tc := range tt {
+		identity, err := annotatedservice.ParseServiceClassIdentity(tc.value)
+		switch {
+		case tc.wantErr && err == nil:
+			t.Errorf("%s: expected error, got nil", tc.name)
+		case !tc.wantErr && err != nil:
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		case !tc.wantErr && !reflect.DeepEqual(identity, tc.expected):
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, identity)
+		}
+	}
+}
+
+func Test_ParseMappings(t *testing.T) {
+	annotations := map[string]string{
+		"primaza.io/service-class-identity": "type=psql",
+		"primaza.io/sed-host":               "db.example.com",
+		"primaza.io/sed-password":           "secret:db-credentials/password",
+		"app":                               "legacy-db",
+	}
+
+	mappings, err := annotatedservice.ParseMappings(annotations)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(mappings.ResourceFields) != 1 {
+		t.Fatalf("expected 1 resource field, got %d", len(mappings.ResourceFields))
+	}
+	if rf := mappings.ResourceFields[0]; rf.Name != "host" || rf.Expression != "self.metadata.annotations['primaza.io/sed-host']" {
+		t.Errorf("unexpected resource field: %v", rf)
+	}
+
+	if len(mappings.SecretRefFields) != 1 {
+		t.Fatalf("expected 1 secret ref field, got %d", len(mappings.SecretRefFields))
+	}
+	sf := mappings.SecretRefFields[0]
+	if sf.Name != "password" || *sf.SecretName.Constant != "db-credentials" || *sf.SecretKey.Constant != "password" {
+		t.Errorf("unexpected secret ref field: %v", sf)
+	}
+
+	for _, v := range []string{"secret:db-credentials", "secret:/password", "secret:db-credentials/"} {
+		if _, err := annotatedservice.ParseMappings(map[string]string{"primaza.io/sed-password": v}); err == nil {
+			t.Errorf("%s: expected error, got nil", v)
+		}
+	}
+}
+
+func Test_ReferencesSecret(t *testing.T) {
+	obj := metav1.ObjectMeta{
+		Annotations: map[string]string{
+			"primaza.io/service-class-identity": "type=psql",
+			"primaza.io/sed-password":           "secret:db-credentials/password",
+		},
+	}
+
+	if !annotatedservice.ReferencesSecret(&obj, "db-credentials") {
+		t.Errorf("expected secret db-credentials to be referenced")
+	}
+	if annotatedservice.ReferencesSecret(&obj, "db") {
+		t.Errorf("expected secret db not to be referenced")
+	}
+}
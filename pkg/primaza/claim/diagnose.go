@@ -0,0 +1,235 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package claim
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/envtag"
+)
+
+// RejectionReason describes why a RegisteredService can not be bound to a ServiceClaim
+type RejectionReason string
+
+const (
+	RejectionReasonIdentityMismatch    RejectionReason = "IdentityMismatch"
+	RejectionReasonEnvironmentExcluded RejectionReason = "EnvironmentExcluded"
+	RejectionReasonAlreadyClaimed      RejectionReason = "AlreadyClaimed"
+	RejectionReasonNotAvailable        RejectionReason = "NotAvailable"
+	RejectionReasonMissingKeys         RejectionReason = "MissingKeys"
+)
+
+// Rejection reports why a RegisteredService can not be bound to a ServiceClaim
+type Rejection struct {
+	RegisteredService string
+	Reason            RejectionReason
+	Details           string
+}
+
+func (r Rejection) String() string {
+	if r.Details == "" {
+		return fmt.Sprintf("%s: %s", r.RegisteredService, r.Reason)
+	}
+	return fmt.Sprintf("%s: %s (%s)", r.RegisteredService, r.Reason, r.Details)
+}
+
+// Diagnosis is the result of the evaluation of a set of RegisteredServices
+// against a ServiceClaim
+type Diagnosis struct {
+	// Eligibles are the RegisteredServices that can be bound to the ServiceClaim
+	Eligibles []v1alpha1.RegisteredService
+
+	// Rejections contains a rejection for each RegisteredService that can
+	// not be bound to the ServiceClaim
+	Rejections []Rejection
+}
+
+// DiagnoseServiceClaim evaluates all the RegisteredServices in the
+// ServiceClaim's namespace against the ServiceClaim
+func DiagnoseServiceClaim(ctx context.Context, cli client.Client, sclaim v1alpha1.ServiceClaim) (*Diagnosis, error) {
+	env, err := Environment(ctx, cli, sclaim)
+	if err != nil {
+		return nil, err
+	}
+
+	var rsl v1alpha1.RegisteredServiceList
+	if err := cli.List(ctx, &rsl, client.InNamespace(sclaim.Namespace)); err != nil {
+		return nil, err
+	}
+
+	d := Diagnose(sclaim, env, rsl.Items)
+	return &d, nil
+}
+
+// Environment returns the environment targeted by the ServiceClaim.
+// If the ServiceClaim targets an application cluster context, the environment
+// is read from the ClusterEnvironment.
+func Environment(ctx context.Context, cli client.Client, sclaim v1alpha1.ServiceClaim) (string, error) {
+	if sclaim.Spec.Target == nil {
+		return "", nil
+	}
+
+	acc := sclaim.Spec.Target.ApplicationClusterContext
+	if acc == nil {
+		return sclaim.Spec.Target.EnvironmentTag, nil
+	}
+
+	var ce v1alpha1.ClusterEnvironment
+	k := types.NamespacedName{Namespace: sclaim.Namespace, Name: acc.ClusterEnvironmentName}
+	if err := cli.Get(ctx, k, &ce); err != nil {
+		return "", err
+	}
+	return ce.Spec.EnvironmentName, nil
+}
+
+// Diagnose evaluates the RegisteredServices against the ServiceClaim, in the
+// given environment. RegisteredServices are checked, in order, for the
+// ServiceClassIdentity, the environment constraints, the state, and the
+// ServiceEndpointDefinition keys. The first failing check is reported as the
+// rejection reason.
+func Diagnose(sclaim v1alpha1.ServiceClaim, environment string, rss []v1alpha1.RegisteredService) Diagnosis {
+	d := Diagnosis{}
+	for _, rs := range rss {
+		if r := evaluate(sclaim, environment, rs); r != nil {
+			d.Rejections = append(d.Rejections, *r)
+			continue
+		}
+		d.Eligibles = append(d.Eligibles, rs)
+	}
+	return d
+}
+
+func evaluate(sclaim v1alpha1.ServiceClaim, environment string, rs v1alpha1.RegisteredService) *Rejection {
+	if !MatchServiceClassIdentity(sclaim.Spec, rs.Spec.ServiceClassIdentity) {
+		return &Rejection{RegisteredService: rs.Name, Reason: RejectionReasonIdentityMismatch}
+	}
+
+	if !envtag.Match(environment, rs.Spec.GetEnvironmentConstraints()) {
+		return &Rejection{
+			RegisteredService: rs.Name,
+			Reason:            RejectionReasonEnvironmentExcluded,
+			Details:           fmt.Sprintf("environment '%s' not in %v", environment, rs.Spec.GetEnvironmentConstraints()),
+		}
+	}
+
+	if rs.Status.State != v1alpha1.RegisteredServiceStateAvailable && !rs.IsClaimedBy(sclaim.Status.ClaimID) {
+		if rs.Status.State == v1alpha1.RegisteredServiceStateClaimed {
+			return &Rejection{RegisteredService: rs.Name, Reason: RejectionReasonAlreadyClaimed}
+		}
+		return &Rejection{
+			RegisteredService: rs.Name,
+			Reason:            RejectionReasonNotAvailable,
+			Details:           fmt.Sprintf("state is '%s'", rs.Status.State),
+		}
+	}
+
+	if mk := missingKeys(sclaim.Spec.ServiceEndpointDefinitionKeys, rs); len(mk) > 0 {
+		return &Rejection{
+			RegisteredService: rs.Name,
+			Reason:            RejectionReasonMissingKeys,
+			Details:           strings.Join(mk, ", "),
+		}
+	}
+
+	return nil
+}
+
+func missingKeys(keys []string, rs v1alpha1.RegisteredService) []string {
+	available := make([]string, 0, len(rs.Spec.ServiceEndpointDefinition))
+	for _, sed := range rs.Spec.ServiceEndpointDefinition {
+		available = append(available, sed.Name)
+	}
+
+	mk := []string{}
+	for _, k := range keys {
+		if !isKeySubset([]string{k}, available) {
+			mk = append(mk, k)
+		}
+	}
+	return mk
+}
+
+// Summary returns a short description of the Diagnosis, counting the
+// rejections by reason
+func (d Diagnosis) Summary() string {
+	counts := map[RejectionReason]int{}
+	for _, r := range d.Rejections {
+		counts[r.Reason]++
+	}
+
+	reasons := make([]string, 0, len(counts))
+	for r, c := range counts {
+		reasons = append(reasons, fmt.Sprintf("%s=%d", r, c))
+	}
+	sort.Strings(reasons)
+
+	return fmt.Sprintf("%d eligible, %d rejected (%s)", len(d.Eligibles), len(d.Rejections), strings.Join(reasons, ", "))
+}
+
+// Report returns a human readable report of the rejections, whose length
+// does not exceed maxLength. Rejections not fitting the report are counted.
+func (d Diagnosis) Report(maxLength int) string {
+	if len(d.Rejections) == 0 {
+		return d.Summary()
+	}
+
+	b := strings.Builder{}
+	b.WriteString(d.Summary())
+	b.WriteString(": ")
+	for i, r := range d.Rejections {
+		s := r.String()
+		if i > 0 {
+			s = "; " + s
+		}
+
+		more := ""
+		if left := len(d.Rejections) - i - 1; left > 0 {
+			more = fmt.Sprintf("; ... %d more", left)
+		}
+		if b.Len()+len(s)+len(more) > maxLength {
+			tail := fmt.Sprintf("; ... %d more", len(d.Rejections)-i)
+			if b.Len()+len(tail) > maxLength {
+				return truncate(b.String(), maxLength)
+			}
+			b.WriteString(tail)
+			return b.String()
+		}
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+// truncate returns the longest prefix of s not exceeding maxLength bytes
+// that does not split a multi-byte character
+func truncate(s string, maxLength int) string {
+	if len(s) <= maxLength {
+		return s
+	}
+	n := maxLength
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
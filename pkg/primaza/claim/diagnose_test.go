@@ -0,0 +1,170 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package claim_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/primaza/claim"
+)
+
+func diagnosedService(name string, state v1alpha1.RegisteredServiceState, engine string, environments []string, keys ...string) v1alpha1.RegisteredService {
+	rs := v1alpha1.RegisteredService{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "primaza-system"},
+		Spec: v1alpha1.RegisteredServiceSpec{
+			ServiceClassIdentity: []v1alpha1.ServiceClassIdentityItem{{Name: "engine", Value: engine}},
+		},
+		Status: v1alpha1.RegisteredServiceStatus{State: state},
+	}
+	if environments != nil {
+		rs.Spec.Constraints = &v1alpha1.RegisteredServiceConstraints{Environments: environments}
+	}
+	for _, k := range keys {
+		rs.Spec.ServiceEndpointDefinition = append(rs.Spec.ServiceEndpointDefinition, v1alpha1.ServiceEndpointDefinitionItem{Name: k, Value: k})
+	}
+	return rs
+}
+
+func diagnosedClaim() v1alpha1.ServiceClaim {
+	return v1alpha1.ServiceClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "claim", Namespace: "primaza-system"},
+		Spec: v1alpha1.ServiceClaimSpec{
+			ServiceClassIdentity:          []v1alpha1.ServiceClassIdentityItem{{Name: "engine", Value: "postgres"}},
+			ServiceEndpointDefinitionKeys: []string{"host", "password"},
+			Target:                        &v1alpha1.ServiceClaimTarget{EnvironmentTag: "dev"},
+		},
+	}
+}
+
+func diagnosedServices() []v1alpha1.RegisteredService {
+	return []v1alpha1.RegisteredService{
+		diagnosedService("mysql", v1alpha1.RegisteredServiceStateAvailable, "mysql", nil, "host", "password"),
+		diagnosedService("prod-only", v1alpha1.RegisteredServiceStateAvailable, "postgres", []string{"prod"}, "host", "password"),
+		diagnosedService("claimed", v1alpha1.RegisteredServiceStateClaimed, "postgres", nil, "host", "password"),
+		diagnosedService("unreachable", v1alpha1.RegisteredServiceStateUnreachable, "postgres", nil, "host", "password"),
+		diagnosedService("no-password", v1alpha1.RegisteredServiceStateAvailable, "postgres", nil, "host"),
+		diagnosedService("eligible", v1alpha1.RegisteredServiceStateAvailable, "postgres", []string{"!prod"}, "host", "password"),
+	}
+}
+
+func Test_Diagnose(t *testing.T) {
+	d := claim.Diagnose(diagnosedClaim(), "dev", diagnosedServices())
+
+	if len(d.Eligibles) != 1 || d.Eligibles[0].Name != "eligible" {
+		t.Errorf("expected only 'eligible' to be eligible, got %v", d.Eligibles)
+	}
+
+	want := map[string]claim.RejectionReason{
+		"mysql":       claim.RejectionReasonIdentityMismatch,
+		"prod-only":   claim.RejectionReasonEnvironmentExcluded,
+		"claimed":     claim.RejectionReasonAlreadyClaimed,
+		"unreachable": claim.RejectionReasonNotAvailable,
+		"no-password": claim.RejectionReasonMissingKeys,
+	}
+	if len(d.Rejections) != len(want) {
+		t.Fatalf("expected %d rejections, got %d", len(want), len(d.Rejections))
+	}
+	for _, r := range d.Rejections {
+		if want[r.RegisteredService] != r.Reason {
+			t.Errorf("%s: expected reason %s, got %s", r.RegisteredService, want[r.RegisteredService], r.Reason)
+		}
+	}
+
+	if d.Rejections[4].Details != "password" {
+		t.Errorf("expected missing key 'password', got '%s'", d.Rejections[4].Details)
+	}
+}
+
+func Test_DiagnosisReportIsBounded(t *testing.T) {
+	d := claim.Diagnose(diagnosedClaim(), "dev", diagnosedServices())
+
+	full := d.Report(4096)
+	if !strings.HasPrefix(full, "1 eligible, 5 rejected") || !strings.Contains(full, "no-password: MissingKeys (password)") {
+		t.Errorf("unexpected report: %s", full)
+	}
+
+	for _, l := range []int{10, 100, 150} {
+		r := d.Report(l)
+		if len(r) > l {
+			t.Errorf("report longer than %d: %s", l, r)
+		}
+	}
+
+	if r := d.Report(150); !strings.Contains(r, "more") {
+		t.Errorf("expected truncated report to count the missing rejections: %s", r)
+	}
+}
+
+func Test_DiagnosisReportKeepsMultiByteCharacters(t *testing.T) {
+	d := claim.Diagnosis{
+		Rejections: []claim.Rejection{
+			{RegisteredService: "base-données", Reason: claim.RejectionReasonMissingKeys, Details: "mot-de-passe-été"},
+			{RegisteredService: "服务", Reason: claim.RejectionReasonMissingKeys, Details: "密码"},
+		},
+	}
+
+	full := d.Report(4096)
+	for l := 0; l <= len(full); l++ {
+		r := d.Report(l)
+		if len(r) > l {
+			t.Errorf("report longer than %d: %s", l, r)
+		}
+		if !utf8.ValidString(r) {
+			t.Errorf("report truncated to %d is not valid UTF-8: %q", l, r)
+		}
+	}
+}
+
+func Test_DiagnoseServiceClaim(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	sclaim := diagnosedClaim()
+	sclaim.Spec.Target = &v1alpha1.ServiceClaimTarget{
+		ApplicationClusterContext: &v1alpha1.ServiceClaimApplicationClusterContext{ClusterEnvironmentName: "worker", Namespace: "apps"},
+	}
+	ce := v1alpha1.ClusterEnvironment{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "primaza-system"},
+		Spec:       v1alpha1.ClusterEnvironmentSpec{EnvironmentName: "prod"},
+	}
+
+	b := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&ce)
+	for _, rs := range diagnosedServices() {
+		rs := rs
+		b = b.WithObjects(&rs)
+	}
+
+	d, err := claim.DiagnoseServiceClaim(context.Background(), b.Build(), sclaim)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 'eligible' excludes the prod environment, while 'prod-only' is not available in dev
+	if len(d.Eligibles) != 1 || d.Eligibles[0].Name != "prod-only" {
+		t.Errorf("expected only 'prod-only' to be eligible, got %s", d.Summary())
+	}
+}
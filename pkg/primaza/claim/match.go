@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package claim
+
+import (
+	"slices"
+
+	"github.com/primaza/primaza/api/v1alpha1"
+)
+
+// MatchServiceClassIdentity returns true if the given ServiceClassIdentity
+// satisfies the ServiceClaim's ServiceClassIdentity and all of its
+// ServiceClassIdentityExpressions
+func MatchServiceClassIdentity(spec v1alpha1.ServiceClaimSpec, sci []v1alpha1.ServiceClassIdentityItem) bool {
+	if !isSubset(spec.ServiceClassIdentity, sci) {
+		return false
+	}
+
+	for _, r := range spec.ServiceClassIdentityExpressions {
+		if !MatchRequirement(r, sci) {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchRequirement returns true if the given ServiceClassIdentity
+// satisfies the requirement
+func MatchRequirement(r v1alpha1.ServiceClassIdentityRequirement, sci []v1alpha1.ServiceClassIdentityItem) bool {
+	if r.Operator == v1alpha1.ServiceClassIdentityOperatorVersionRange {
+		return matchVersionRange(r, sci)
+	}
+
+	exists, in := false, false
+	for _, i := range sci {
+		if i.Name != r.Name {
+			continue
+		}
+
+		exists = true
+		if slices.Contains(r.Values, i.Value) {
+			in = true
+		}
+	}
+
+	switch r.Operator {
+	case v1alpha1.ServiceClassIdentityOperatorIn:
+		return in
+	case v1alpha1.ServiceClassIdentityOperatorNotIn:
+		return !in
+	case v1alpha1.ServiceClassIdentityOperatorExists:
+		return exists
+	case v1alpha1.ServiceClassIdentityOperatorDoesNotExist:
+		return !exists
+	default:
+		return false
+	}
+}
+
+// FilterServiceCatalog returns the services of the ServiceCatalog that
+// match the ServiceClaim's ServiceClassIdentity and provide all the
+// requested ServiceEndpointDefinitionKeys
+func FilterServiceCatalog(spec v1alpha1.ServiceClaimSpec, catalog v1alpha1.ServiceCatalog) []v1alpha1.ServiceCatalogService {
+	scs := []v1alpha1.ServiceCatalogService{}
+	for _, s := range catalog.Spec.Services {
+		if MatchServiceClassIdentity(spec, s.ServiceClassIdentity) &&
+			isKeySubset(spec.ServiceEndpointDefinitionKeys, s.ServiceEndpointDefinitionKeys) {
+			scs = append(scs, s)
+		}
+	}
+	return scs
+}
+
+// Ref. https://stackoverflow.com/a/18879994/547840
+func isSubset(serviceClaim, registeredService []v1alpha1.ServiceClassIdentityItem) bool {
+	set := make(map[v1alpha1.ServiceClassIdentityItem]int)
+	for _, value := range registeredService {
+		set[value] += 1
+	}
+
+	for _, value := range serviceClaim {
+		if count, found := set[value]; !found {
+			return false
+		} else if count < 1 {
+			return false
+		} else {
+			set[value] = count - 1
+		}
+	}
+
+	return true
+}
+
+func isKeySubset(keys, available []string) bool {
+	for _, k := range keys {
+		if !slices.Contains(available, k) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package claim_test
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/primaza/claim"
+)
+
+var postgres14 = []v1alpha1.ServiceClassIdentityItem{
+	{Name: "engine", Value: "postgres"},
+	{Name: "version", Value: "14"},
+	{Name: "region", Value: "us-east"},
+}
+
+func Test_MatchServiceClassIdentity(t *testing.T) {
+	type test struct {
+		name        string
+		identity    []v1alpha1.ServiceClassIdentityItem
+		expressions []v1alpha1.ServiceClassIdentityRequirement
+		want        bool
+	}
+
+	tt := []test{
+		{name: "empty claim", want: true},
+		{name: "subset", identity: []v1alpha1.ServiceClassIdentityItem{{Name: "engine", Value: "postgres"}}, want: true},
+		{name: "not a subset", identity: []v1alpha1.ServiceClassIdentityItem{{Name: "engine", Value: "mysql"}}, want: false},
+		{
+			name:     "in",
+			identity: []v1alpha1.ServiceClassIdentityItem{{Name: "engine", Value: "postgres"}},
+			expressions: []v1alpha1.ServiceClassIdentityRequirement{
+				{Name: "version", Operator: v1alpha1.ServiceClassIdentityOperatorIn, Values: []string{"14", "15"}},
+			},
+			want: true,
+		},
+		{
+			name: "not in",
+			expressions: []v1alpha1.ServiceClassIdentityRequirement{
+				{Name: "version", Operator: v1alpha1.ServiceClassIdentityOperatorIn, Values: []string{"15", "16"}},
+			},
+			want: false,
+		},
+		{
+			name: "notin on missing attribute",
+			expressions: []v1alpha1.ServiceClassIdentityRequirement{
+				{Name: "tier", Operator: v1alpha1.ServiceClassIdentityOperatorNotIn, Values: []string{"premium"}},
+			},
+			want: true,
+		},
+		{
+			name: "notin excluded",
+			expressions: []v1alpha1.ServiceClassIdentityRequirement{
+				{Name: "region", Operator: v1alpha1.ServiceClassIdentityOperatorNotIn, Values: []string{"us-east"}},
+			},
+			want: false,
+		},
+		{
+			name: "exists",
+			expressions: []v1alpha1.ServiceClassIdentityRequirement{
+				{Name: "region", Operator: v1alpha1.ServiceClassIdentityOperatorExists},
+			},
+			want: true,
+		},
+		{
+			name: "does not exist",
+			expressions: []v1alpha1.ServiceClassIdentityRequirement{
+				{Name: "region", Operator: v1alpha1.ServiceClassIdentityOperatorDoesNotExist},
+			},
+			want: false,
+		},
+		{
+			name: "all requirements must be satisfied",
+			expressions: []v1alpha1.ServiceClassIdentityRequirement{
+				{Name: "version", Operator: v1alpha1.ServiceClassIdentityOperatorIn, Values: []string{"14"}},
+				{Name: "tier", Operator: v1alpha1.ServiceClassIdentityOperatorExists},
+			},
+			want: false,
+		},
+	}
+
+	for _, te := range tt {
+		spec := v1alpha1.ServiceClaimSpec{ServiceClassIdentity: te.identity, ServiceClassIdentityExpressions: te.expressions}
+		if got := claim.MatchServiceClassIdentity(spec, postgres14); got != te.want {
+			t.Errorf("%s: expected %v, got %v", te.name, te.want, got)
+		}
+	}
+}
+
+func Test_FilterServiceCatalog(t *testing.T) {
+	catalog := v1alpha1.ServiceCatalog{
+		ObjectMeta: metav1.ObjectMeta{Name: "dev"},
+		Spec: v1alpha1.ServiceCatalogSpec{
+			Services: []v1alpha1.ServiceCatalogService{
+				{Name: "pg14", ServiceClassIdentity: postgres14, ServiceEndpointDefinitionKeys: []string{"host", "password"}},
+				{
+					Name: "pg13",
+					ServiceClassIdentity: []v1alpha1.ServiceClassIdentityItem{
+						{Name: "engine", Value: "postgres"},
+						{Name: "version", Value: "13"},
+					},
+					ServiceEndpointDefinitionKeys: []string{"host", "password"},
+				},
+				{Name: "pg14-nopassword", ServiceClassIdentity: postgres14, ServiceEndpointDefinitionKeys: []string{"host"}},
+			},
+		},
+	}
+
+	spec := v1alpha1.ServiceClaimSpec{
+		ServiceClassIdentity: []v1alpha1.ServiceClassIdentityItem{{Name: "engine", Value: "postgres"}},
+		ServiceClassIdentityExpressions: []v1alpha1.ServiceClassIdentityRequirement{
+			{Name: "version", Operator: v1alpha1.ServiceClassIdentityOperatorIn, Values: []string{"14", "15"}},
+		},
+		ServiceEndpointDefinitionKeys: []string{"password"},
+	}
+
+	scs := claim.FilterServiceCatalog(spec, catalog)
+	if len(scs) != 1 || scs[0].Name != "pg14" {
+		t.Errorf("expected only pg14 to match, got %v", scs)
+	}
+}
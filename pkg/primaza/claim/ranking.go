@@ -0,0 +1,217 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package claim
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/primaza/constants"
+)
+
+// RankingContext contains the information about the ServiceClaim being
+// resolved that Scorers may use to evaluate a RegisteredService
+type RankingContext struct {
+	// ServiceClaim is the claim being resolved
+	ServiceClaim v1alpha1.ServiceClaim
+
+	// ClaimsCount maps the name of a RegisteredService to the number of
+	// ServiceClaims currently bound to it
+	ClaimsCount map[string]int
+
+	// LocalClusterEnvironments contains the names of the ClusterEnvironments
+	// the ServiceClaim targets
+	LocalClusterEnvironments []string
+}
+
+// Scorer evaluates a RegisteredService for a ServiceClaim.
+// The higher the score, the better the RegisteredService fits the ServiceClaim.
+type Scorer interface {
+	Name() string
+	Score(RankingContext, v1alpha1.RegisteredService) int
+}
+
+// Candidate is a RegisteredService eligible to be bound to a ServiceClaim
+// together with the scores it obtained
+type Candidate struct {
+	RegisteredService v1alpha1.RegisteredService
+	Score             int
+	Scores            map[string]int
+}
+
+// Ranker sorts eligible RegisteredServices using a set of Scorers
+type Ranker struct {
+	scorers []Scorer
+}
+
+// NewRanker creates a Ranker that evaluates candidates with the given Scorers
+func NewRanker(scorers ...Scorer) *Ranker {
+	return &Ranker{scorers: scorers}
+}
+
+// NewDefaultRanker creates a Ranker which prefers, in order, healthy,
+// same-cluster, less claimed and SLA-backed RegisteredServices
+func NewDefaultRanker() *Ranker {
+	return NewRanker(
+		HealthScorer{Weight: 8},
+		LocalityScorer{Weight: 4},
+		ClaimsScorer{Weight: 2},
+		NewSLAScorer(1),
+	)
+}
+
+// Rank scores the given RegisteredServices and returns them as Candidates
+// sorted from the best to the worst. Ties are broken by name, so that the
+// result is stable across reconciliations.
+func (r *Ranker) Rank(rc RankingContext, rss []v1alpha1.RegisteredService) []Candidate {
+	cc := make([]Candidate, 0, len(rss))
+	for _, rs := range rss {
+		c := Candidate{RegisteredService: rs, Scores: map[string]int{}}
+		for _, s := range r.scorers {
+			v := s.Score(rc, rs)
+			c.Scores[s.Name()] = v
+			c.Score += v
+		}
+		cc = append(cc, c)
+	}
+
+	sort.SliceStable(cc, func(i, j int) bool {
+		if cc[i].Score != cc[j].Score {
+			return cc[i].Score > cc[j].Score
+		}
+		return cc[i].RegisteredService.Name < cc[j].RegisteredService.Name
+	})
+	return cc
+}
+
+// String returns a human readable representation of the candidate's scores
+func (c Candidate) String() string {
+	names := make([]string, 0, len(c.Scores))
+	for n := range c.Scores {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	ss := make([]string, 0, len(names))
+	for _, n := range names {
+		ss = append(ss, fmt.Sprintf("%s=%d", n, c.Scores[n]))
+	}
+	return fmt.Sprintf("'%s' with score %d (%s)", c.RegisteredService.Name, c.Score, strings.Join(ss, ", "))
+}
+
+// DescribeSelection returns a message describing the selected candidate and
+// its runner-up, if any. Candidates are expected to be sorted as returned by
+// Rank.
+func DescribeSelection(cc []Candidate) string {
+	switch len(cc) {
+	case 0:
+		return "no candidate available"
+	case 1:
+		return fmt.Sprintf("selected %s; no runner-up", cc[0])
+	default:
+		return fmt.Sprintf("selected %s; runner-up %s", cc[0], cc[1])
+	}
+}
+
+// HealthScorer prefers RegisteredServices whose availability has been
+// verified by a health check
+type HealthScorer struct {
+	Weight int
+}
+
+func (s HealthScorer) Name() string {
+	return "health"
+}
+
+func (s HealthScorer) Score(_ RankingContext, rs v1alpha1.RegisteredService) int {
+	if rs.Status.State != v1alpha1.RegisteredServiceStateAvailable {
+		return 0
+	}
+	if rs.Spec.HealthCheck == nil {
+		return s.Weight / 2
+	}
+	return s.Weight
+}
+
+// LocalityScorer prefers RegisteredServices discovered in one of the
+// ClusterEnvironments the ServiceClaim targets
+type LocalityScorer struct {
+	Weight int
+}
+
+func (s LocalityScorer) Name() string {
+	return "locality"
+}
+
+func (s LocalityScorer) Score(rc RankingContext, rs v1alpha1.RegisteredService) int {
+	ce, ok := rs.Annotations[constants.ClusterEnvironmentAnnotation]
+	if !ok || ce == "" {
+		return 0
+	}
+	if slices.Contains(rc.LocalClusterEnvironments, ce) {
+		return s.Weight
+	}
+	return 0
+}
+
+// ClaimsScorer penalizes RegisteredServices proportionally to the number of
+// ServiceClaims already bound to them
+type ClaimsScorer struct {
+	Weight int
+}
+
+func (s ClaimsScorer) Name() string {
+	return "claims"
+}
+
+func (s ClaimsScorer) Score(rc RankingContext, rs v1alpha1.RegisteredService) int {
+	return -rc.ClaimsCount[rs.Name] * s.Weight
+}
+
+// SLAScorer prefers RegisteredServices with a better SLA.
+// If no levels are defined, any RegisteredService declaring an SLA is
+// preferred over the ones not declaring it.
+type SLAScorer struct {
+	Weight int
+	Levels []string
+}
+
+// NewSLAScorer creates an SLAScorer. Levels have to be provided from the best
+// to the worst.
+func NewSLAScorer(weight int, levels ...string) SLAScorer {
+	return SLAScorer{Weight: weight, Levels: levels}
+}
+
+func (s SLAScorer) Name() string {
+	return "sla"
+}
+
+func (s SLAScorer) Score(_ RankingContext, rs v1alpha1.RegisteredService) int {
+	if rs.Spec.SLA == "" {
+		return 0
+	}
+	if len(s.Levels) == 0 {
+		return s.Weight
+	}
+	if i := slices.Index(s.Levels, rs.Spec.SLA); i >= 0 {
+		return (len(s.Levels) - i) * s.Weight
+	}
+	return 0
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package claim_test
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/primaza/claim"
+	"github.com/primaza/primaza/pkg/primaza/constants"
+)
+
+func registeredService(name string, ce string, sla string, healthCheck bool) v1alpha1.RegisteredService {
+	rs := v1alpha1.RegisteredService{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1alpha1.RegisteredServiceSpec{SLA: sla},
+		Status:     v1alpha1.RegisteredServiceStatus{State: v1alpha1.RegisteredServiceStateAvailable},
+	}
+	if ce != "" {
+		rs.Annotations = map[string]string{constants.ClusterEnvironmentAnnotation: ce}
+	}
+	if healthCheck {
+		rs.Spec.HealthCheck = &v1alpha1.HealthCheck{}
+	}
+	return rs
+}
+
+func Test_DefaultRankerSelection(t *testing.T) {
+	type test struct {
+		name     string
+		context  claim.RankingContext
+		services []v1alpha1.RegisteredService
+		want     string
+	}
+
+	tt := []test{
+		{
+			name:     "ties are broken by name",
+			services: []v1alpha1.RegisteredService{registeredService("b", "", "", false), registeredService("a", "", "", false)},
+			want:     "a",
+		},
+		{
+			name:     "health checked service is preferred",
+			services: []v1alpha1.RegisteredService{registeredService("a", "", "", false), registeredService("b", "", "", true)},
+			want:     "b",
+		},
+		{
+			name:     "local service is preferred",
+			context:  claim.RankingContext{LocalClusterEnvironments: []string{"ce"}},
+			services: []v1alpha1.RegisteredService{registeredService("a", "other", "", false), registeredService("b", "ce", "", false)},
+			want:     "b",
+		},
+		{
+			name:     "less claimed service is preferred",
+			context:  claim.RankingContext{ClaimsCount: map[string]int{"a": 1}},
+			services: []v1alpha1.RegisteredService{registeredService("a", "", "", false), registeredService("b", "", "", false)},
+			want:     "b",
+		},
+		{
+			name:     "service with SLA is preferred",
+			services: []v1alpha1.RegisteredService{registeredService("a", "", "", false), registeredService("b", "", "gold", false)},
+			want:     "b",
+		},
+		{
+			name:     "locality outweighs claims count",
+			context:  claim.RankingContext{ClaimsCount: map[string]int{"a": 1}, LocalClusterEnvironments: []string{"ce"}},
+			services: []v1alpha1.RegisteredService{registeredService("a", "ce", "", false), registeredService("b", "", "", false)},
+			want:     "a",
+		},
+	}
+
+	for _, te := range tt {
+		cc := claim.NewDefaultRanker().Rank(te.context, te.services)
+		if got := cc[0].RegisteredService.Name; got != te.want {
+			t.Errorf("%s: expected %s, got %s (%s)", te.name, te.want, got, claim.DescribeSelection(cc))
+		}
+	}
+}
+
+func Test_SLAScorerLevels(t *testing.T) {
+	type test struct {
+		sla  string
+		want int
+	}
+
+	s := claim.NewSLAScorer(1, "gold", "silver", "bronze")
+	tt := []test{
+		{sla: "", want: 0},
+		{sla: "gold", want: 3},
+		{sla: "silver", want: 2},
+		{sla: "bronze", want: 1},
+		{sla: "unknown", want: 0},
+	}
+
+	for _, te := range tt {
+		if got := s.Score(claim.RankingContext{}, registeredService("a", "", te.sla, false)); got != te.want {
+			t.Errorf("sla %s: expected %d, got %d", te.sla, te.want, got)
+		}
+	}
+}
+
+func Test_DescribeSelection(t *testing.T) {
+	r := claim.NewRanker(claim.HealthScorer{Weight: 2})
+
+	if got := claim.DescribeSelection(nil); got != "no candidate available" {
+		t.Errorf("unexpected description: %s", got)
+	}
+
+	cc := r.Rank(claim.RankingContext{}, []v1alpha1.RegisteredService{registeredService("a", "", "", true)})
+	if got, want := claim.DescribeSelection(cc), "selected 'a' with score 2 (health=2); no runner-up"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	cc = r.Rank(claim.RankingContext{}, []v1alpha1.RegisteredService{
+		registeredService("a", "", "", false),
+		registeredService("b", "", "", true),
+	})
+	if got, want := claim.DescribeSelection(cc), "selected 'b' with score 2 (health=2); runner-up 'a' with score 1 (health=1)"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package claim
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"github.com/primaza/primaza/api/v1alpha1"
+)
+
+type comparator struct {
+	operator string
+	version  *version.Version
+}
+
+func (c comparator) match(v *version.Version) bool {
+	switch c.operator {
+	case ">=":
+		return !v.LessThan(c.version)
+	case ">":
+		return c.version.LessThan(v)
+	case "<=":
+		return !c.version.LessThan(v)
+	case "<":
+		return v.LessThan(c.version)
+	case "!=":
+		return v.LessThan(c.version) || c.version.LessThan(v)
+	default:
+		return !v.LessThan(c.version) && !c.version.LessThan(v)
+	}
+}
+
+// VersionRange is a set of version constraints, like ">= 13.2 < 16".
+// Constraints separated by spaces or commas must all be satisfied, while
+// groups of constraints separated by "||" are alternatives.
+type VersionRange [][]comparator
+
+var rangeOperators = []string{">=", "<=", "!=", ">", "<", "="}
+
+// ParseVersionRange parses a version range
+func ParseVersionRange(s string) (VersionRange, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, errors.New("empty version range")
+	}
+
+	vr := VersionRange{}
+	for _, g := range strings.Split(s, "||") {
+		cc, err := parseComparators(g)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version range '%s': %w", s, err)
+		}
+		vr = append(vr, cc)
+	}
+	return vr, nil
+}
+
+func parseComparators(s string) ([]comparator, error) {
+	ff := strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' })
+	if len(ff) == 0 {
+		return nil, errors.New("empty set of constraints")
+	}
+
+	cc := []comparator{}
+	for i := 0; i < len(ff); i++ {
+		f, op := ff[i], "="
+		for _, o := range rangeOperators {
+			if strings.HasPrefix(f, o) {
+				op, f = o, strings.TrimPrefix(f, o)
+				break
+			}
+		}
+
+		// allow spaces between the operator and the version
+		if f == "" {
+			if i+1 == len(ff) {
+				return nil, fmt.Errorf("missing version after '%s'", op)
+			}
+			i++
+			f = ff[i]
+		}
+
+		v, err := parseVersion(f)
+		if err != nil {
+			return nil, err
+		}
+		cc = append(cc, comparator{operator: op, version: v})
+	}
+	return cc, nil
+}
+
+// parseVersion parses a version, accepting also versions made of just the
+// major component, like "16"
+func parseVersion(s string) (*version.Version, error) {
+	if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+	return version.ParseGeneric(s)
+}
+
+// Contains returns true if the version satisfies the range
+func (vr VersionRange) Contains(v *version.Version) bool {
+	for _, cc := range vr {
+		if matchAll(cc, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAll(cc []comparator, v *version.Version) bool {
+	for _, c := range cc {
+		if !c.match(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchVersionRange returns true if the value of the attribute the
+// requirement applies to is a version satisfying the requirement's range
+func matchVersionRange(r v1alpha1.ServiceClassIdentityRequirement, sci []v1alpha1.ServiceClassIdentityItem) bool {
+	if len(r.Values) != 1 {
+		return false
+	}
+
+	vr, err := ParseVersionRange(r.Values[0])
+	if err != nil {
+		return false
+	}
+
+	for _, i := range sci {
+		if i.Name != r.Name {
+			continue
+		}
+
+		if v, err := parseVersion(i.Value); err == nil && vr.Contains(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateVersionRanges checks that all the version ranges defined in the
+// ServiceClaim's ServiceClassIdentityExpressions can be parsed
+func ValidateVersionRanges(spec v1alpha1.ServiceClaimSpec) error {
+	errs := []error{}
+	for _, r := range spec.ServiceClassIdentityExpressions {
+		if r.Operator != v1alpha1.ServiceClassIdentityOperatorVersionRange {
+			continue
+		}
+
+		if len(r.Values) != 1 {
+			errs = append(errs, fmt.Errorf("requirement on '%s' must define exactly one version range", r.Name))
+			continue
+		}
+		if _, err := ParseVersionRange(r.Values[0]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ResolvedVersion returns the highest value satisfying the range of the
+// first attribute constrained by a version range in the ServiceClaim, as
+// defined by the RegisteredService.  It returns an empty string if the
+// ServiceClaim has no version range.
+func ResolvedVersion(spec v1alpha1.ServiceClaimSpec, rs v1alpha1.RegisteredService) string {
+	for _, r := range spec.ServiceClassIdentityExpressions {
+		if r.Operator != v1alpha1.ServiceClassIdentityOperatorVersionRange {
+			continue
+		}
+
+		_, value := highestVersion(r, rs.Spec.ServiceClassIdentity)
+		return value
+	}
+	return ""
+}
+
+// SelectHighestVersions filters the RegisteredServices keeping only the ones
+// with the highest version for the attributes constrained by a version range
+// in the ServiceClaim. Attributes are evaluated in the order they appear in
+// the ServiceClaim's expressions.
+func SelectHighestVersions(spec v1alpha1.ServiceClaimSpec, rss []v1alpha1.RegisteredService) []v1alpha1.RegisteredService {
+	for _, r := range spec.ServiceClassIdentityExpressions {
+		if r.Operator != v1alpha1.ServiceClassIdentityOperatorVersionRange {
+			continue
+		}
+
+		var highest *version.Version
+		selected := []v1alpha1.RegisteredService{}
+		for _, rs := range rss {
+			v, _ := highestVersion(r, rs.Spec.ServiceClassIdentity)
+			switch {
+			case v == nil:
+				continue
+			case highest == nil || highest.LessThan(v):
+				highest = v
+				selected = []v1alpha1.RegisteredService{rs}
+			case !v.LessThan(highest):
+				selected = append(selected, rs)
+			}
+		}
+		rss = selected
+	}
+	return rss
+}
+
+// highestVersion returns the highest version, and its original value, among
+// the values of the requirement's attribute that satisfy its range
+func highestVersion(r v1alpha1.ServiceClassIdentityRequirement, sci []v1alpha1.ServiceClassIdentityItem) (*version.Version, string) {
+	if len(r.Values) != 1 {
+		return nil, ""
+	}
+	vr, err := ParseVersionRange(r.Values[0])
+	if err != nil {
+		return nil, ""
+	}
+
+	var highest *version.Version
+	value := ""
+	for _, i := range sci {
+		if i.Name != r.Name {
+			continue
+		}
+
+		if v, err := parseVersion(i.Value); err == nil && vr.Contains(v) && (highest == nil || highest.LessThan(v)) {
+			highest, value = v, i.Value
+		}
+	}
+	return highest, value
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package claim_test
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+
+	"github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/primaza/claim"
+)
+
+func Test_VersionRangeContains(t *testing.T) {
+	type test struct {
+		versionRange string
+		version      string
+		want         bool
+	}
+
+	tt := []test{
+		{versionRange: ">= 13.2 < 16", version: "13.2", want: true},
+		{versionRange: ">= 13.2 < 16", version: "13.2.0", want: true},
+		{versionRange: ">= 13.2 < 16", version: "13.1.9", want: false},
+		{versionRange: ">= 13.2 < 16", version: "15.4", want: true},
+		{versionRange: ">= 13.2 < 16", version: "16.0", want: false},
+		{versionRange: ">=13.2, <16", version: "14.0", want: true},
+		{versionRange: "> 14", version: "14.0", want: false},
+		{versionRange: "<= 14", version: "14.0.0", want: true},
+		{versionRange: "!= 14", version: "14.0", want: false},
+		{versionRange: "14", version: "14.0", want: true},
+		{versionRange: "< 12 || >= 15", version: "13.0", want: false},
+		{versionRange: "< 12 || >= 15", version: "15.1", want: true},
+		{versionRange: "< 12 || >= 15", version: "v11.2", want: true},
+	}
+
+	for _, te := range tt {
+		vr, err := claim.ParseVersionRange(te.versionRange)
+		if err != nil {
+			t.Errorf("unexpected error parsing '%s': %v", te.versionRange, err)
+			continue
+		}
+		if got := vr.Contains(version.MustParseGeneric(te.version)); got != te.want {
+			t.Errorf("'%s' contains '%s': expected %v, got %v", te.versionRange, te.version, te.want, got)
+		}
+	}
+}
+
+func Test_ParseVersionRangeErrors(t *testing.T) {
+	for _, s := range []string{"", ">=", ">= abc", "< 12 ||", ">= 1.x"} {
+		if _, err := claim.ParseVersionRange(s); err == nil {
+			t.Errorf("expected an error parsing '%s'", s)
+		}
+	}
+}
+
+func Test_SelectHighestVersions(t *testing.T) {
+	rs := func(name, v string) v1alpha1.RegisteredService {
+		return v1alpha1.RegisteredService{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1alpha1.RegisteredServiceSpec{
+				ServiceClassIdentity: []v1alpha1.ServiceClassIdentityItem{
+					{Name: "engine", Value: "postgres"},
+					{Name: "version", Value: v},
+				},
+			},
+		}
+	}
+
+	spec := v1alpha1.ServiceClaimSpec{
+		ServiceClassIdentity: []v1alpha1.ServiceClassIdentityItem{{Name: "engine", Value: "postgres"}},
+		ServiceClassIdentityExpressions: []v1alpha1.ServiceClassIdentityRequirement{
+			{Name: "version", Operator: v1alpha1.ServiceClassIdentityOperatorVersionRange, Values: []string{">= 13.2 < 16"}},
+		},
+	}
+
+	var matching []v1alpha1.RegisteredService
+	for _, r := range []v1alpha1.RegisteredService{rs("a", "13.1"), rs("b", "14.2"), rs("c", "15.1"), rs("d", "15.1.0"), rs("e", "16.0")} {
+		if claim.MatchServiceClassIdentity(spec, r.Spec.ServiceClassIdentity) {
+			matching = append(matching, r)
+		}
+	}
+	if len(matching) != 3 {
+		t.Fatalf("expected 3 matching services, got %d", len(matching))
+	}
+
+	selected := claim.SelectHighestVersions(spec, matching)
+	if len(selected) != 2 || selected[0].Name != "c" || selected[1].Name != "d" {
+		t.Errorf("expected services c and d to be selected, got %v", selected)
+	}
+
+	if got := claim.ResolvedVersion(spec, selected[0]); got != "15.1" {
+		t.Errorf("expected resolved version 15.1, got %s", got)
+	}
+}
+
+func Test_ResolvedVersionMultipleValues(t *testing.T) {
+	spec := v1alpha1.ServiceClaimSpec{
+		ServiceClassIdentityExpressions: []v1alpha1.ServiceClassIdentityRequirement{
+			{Name: "version", Operator: v1alpha1.ServiceClassIdentityOperatorVersionRange, Values: []string{">= 13.2 < 16"}},
+		},
+	}
+	rs := v1alpha1.RegisteredService{
+		Spec: v1alpha1.RegisteredServiceSpec{
+			ServiceClassIdentity: []v1alpha1.ServiceClassIdentityItem{
+				{Name: "version", Value: "12"},
+				{Name: "version", Value: "17"},
+				{Name: "version", Value: "14.1"},
+				{Name: "version", Value: "15"},
+				{Name: "version", Value: "13.4"},
+			},
+		},
+	}
+
+	if got := claim.ResolvedVersion(spec, rs); got != "15" {
+		t.Errorf("expected resolved version 15, got %s", got)
+	}
+}
+
+func Test_ValidateVersionRanges(t *testing.T) {
+	spec := v1alpha1.ServiceClaimSpec{
+		ServiceClassIdentityExpressions: []v1alpha1.ServiceClassIdentityRequirement{
+			{Name: "version", Operator: v1alpha1.ServiceClassIdentityOperatorVersionRange, Values: []string{">= 13.2 < 16"}},
+		},
+	}
+	if err := claim.ValidateVersionRanges(spec); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	spec.ServiceClassIdentityExpressions[0].Values = []string{">= thirteen"}
+	if err := claim.ValidateVersionRanges(spec); err == nil {
+		t.Errorf("expected an error validating an invalid range")
+	}
+}
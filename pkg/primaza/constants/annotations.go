@@ -26,4 +26,22 @@ const (
 	ServiceNameAnnotation       = "primaza.io/service-name"
 	ServiceNamespaceAnnotation  = "primaza.io/service-namespace"
 	ServiceUIDAnnotation        = "primaza.io/service-uid"
+	MissingKeysAnnotation       = "primaza.io/missing-keys"
+	ServiceNotReadyAnnotation   = "primaza.io/service-not-ready"
+
+	// Service Resource Annotations
+	// Resources with this annotation set to "true" are not registered
+	IgnoreAnnotation = "primaza.io/ignore"
+	// Services with this annotation are registered without a ServiceClass.
+	// The value is a comma-separated list of key=value pairs.
+	ServiceClassIdentityAnnotation = "primaza.io/service-class-identity"
+	// The Service Endpoint Definition key is appended to the prefix
+	SEDAnnotationPrefix = "primaza.io/sed-"
+
+	// ServiceClaim Annotations
+	DryRunAnnotation = "primaza.io/dry-run"
+
+	// Workload Pod Template Annotations
+	// The ServiceBinding name is appended to the prefix
+	SecretChecksumAnnotationPrefix = "secret-checksum.primaza.io/"
 )
@@ -28,6 +28,16 @@ const (
 	// Reasons for status condition
 	NoMatchingServiceFoundReason = "NoMatchingServiceFound"
 	ValidationErrorReason        = "ValidationError"
+	CandidateSelectedReason      = "CandidateSelected"
+	FailoverReason               = "Failover"
+	FailoverFailedReason         = "FailoverFailed"
+	MatchingServicesFoundReason  = "MatchingServicesFound"
+	DryRunReason                 = "DryRun"
+	SecretRotatedReason          = "SecretRotated"
+	MappingSucceededReason       = "MappingSucceeded"
+	MappingErrorReason           = "MappingError"
+	TemplateErrorReason          = "TemplateError"
+	InvalidAnnotationsReason     = "InvalidAnnotations"
 
 	// ServiceBinding Annotations
 	BoundRegisteredServiceNameAnnotation = "primaza.io/registered-service-name"
@@ -5,4 +5,6 @@ const (
 	PrimazaClusterEnvironmentLabel string = "primaza.io/cluster-environment"
 	PrimazaNamespaceTypeLabel      string = "primaza.io/namespace-type"
 	PrimazaNamespaceLabel          string = "primaza.io/namespace"
+	PrimazaInventoryLabel          string = "primaza.io/inventory"
+	PrimazaInventorySourceLabel    string = "primaza.io/inventory-source"
 )
@@ -86,6 +86,7 @@ func pushServiceBindingToNamespace(
 			ServiceEndpointDefinitionSecret: sc.Name,
 			Application:                     sc.Spec.Application,
 			Envs:                            sc.Spec.Envs,
+			RestartPolicy:                   sc.Spec.RestartPolicy,
 		},
 	}
 
@@ -101,6 +102,7 @@ func pushServiceBindingToNamespace(
 			ServiceEndpointDefinitionSecret: sc.Name,
 			Application:                     sc.Spec.Application,
 			Envs:                            sc.Spec.Envs,
+			RestartPolicy:                   sc.Spec.RestartPolicy,
 		}
 		return nil
 	})
@@ -0,0 +1,116 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/primaza/constants"
+)
+
+// Importer imports the services of an inventory as RegisteredServices.
+// Imported RegisteredServices are labeled with their source, so that
+// services removed from the inventory can be deleted.
+type Importer struct {
+	Client    client.Client
+	Namespace string
+	Source    string
+
+	// Owner, when set, is the controller of the imported RegisteredServices
+	Owner client.Object
+}
+
+// Sync makes the RegisteredServices imported from the source match the
+// inventory: missing services are created, existing ones are updated, and
+// the ones no more listed are deleted.  RegisteredServices that have not
+// been imported from the source are never modified.
+func (i *Importer) Sync(ctx context.Context, inventory Inventory) error {
+	if err := ValidateSource(i.Source); err != nil {
+		return err
+	}
+
+	errs := []error{}
+	names := map[string]struct{}{}
+	for _, s := range inventory.Services {
+		names[s.Name] = struct{}{}
+		if err := i.apply(ctx, s); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	rsl := v1alpha1.RegisteredServiceList{}
+	if err := i.Client.List(ctx, &rsl,
+		client.InNamespace(i.Namespace),
+		client.MatchingLabels{constants.PrimazaInventorySourceLabel: i.Source}); err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	for idx := range rsl.Items {
+		rs := &rsl.Items[idx]
+		if _, found := names[rs.Name]; found {
+			continue
+		}
+		log.FromContext(ctx).Info("Deleting registered service removed from inventory", "name", rs.Name, "source", i.Source)
+		if err := i.Client.Delete(ctx, rs); client.IgnoreNotFound(err) != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateSource returns an error if the source can not be used to label the
+// imported RegisteredServices
+func ValidateSource(source string) error {
+	if errs := validation.IsValidLabelValue(source); len(errs) > 0 {
+		return fmt.Errorf("invalid inventory source '%s': %s", source, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func (i *Importer) apply(ctx context.Context, s Service) error {
+	rs := v1alpha1.RegisteredService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      s.Name,
+			Namespace: i.Namespace,
+		},
+	}
+	op, err := controllerutil.CreateOrUpdate(ctx, i.Client, &rs, func() error {
+		if rs.ResourceVersion != "" && rs.Labels[constants.PrimazaInventorySourceLabel] != i.Source {
+			return fmt.Errorf("registered service '%s' is not imported from inventory '%s'", rs.Name, i.Source)
+		}
+		metav1.SetMetaDataLabel(&rs.ObjectMeta, constants.PrimazaInventorySourceLabel, i.Source)
+		rs.Spec = s.RegisteredServiceSpec
+		if i.Owner != nil {
+			return controllerutil.SetControllerReference(i.Owner, &rs, i.Client.Scheme())
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("Imported registered service", "name", rs.Name, "source", i.Source, "operation", op)
+	return nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+
+	"github.com/primaza/primaza/api/v1alpha1"
+)
+
+// ConfigMapKey is the key of inventory ConfigMaps holding the inventory
+const ConfigMapKey = "inventory.yaml"
+
+// ConfigMapSourcePrefix prefixes the sources of the inventories held by
+// ConfigMaps, so that they never collide with the sources of the inventories
+// imported with the inventory command
+const ConfigMapSourcePrefix = "configmap-"
+
+// ConfigMapSource returns the source of the inventory held by the ConfigMap
+// with the given name
+func ConfigMapSource(name string) string {
+	return ConfigMapSourcePrefix + name
+}
+
+// Inventory lists the services to be registered
+type Inventory struct {
+	Services []Service `json:"services"`
+}
+
+// Service is a service to be registered.  It is published as a
+// RegisteredService with the given name and specification.
+type Service struct {
+	Name string `json:"name"`
+
+	v1alpha1.RegisteredServiceSpec `json:",inline"`
+}
+
+// Parse reads and validates an inventory in YAML or JSON format
+func Parse(data []byte) (*Inventory, error) {
+	inventory := Inventory{}
+	if err := yaml.UnmarshalStrict(data, &inventory); err != nil {
+		return nil, err
+	}
+	if err := inventory.Validate(); err != nil {
+		return nil, err
+	}
+	return &inventory, nil
+}
+
+// Validate checks that the services can be registered
+func (i *Inventory) Validate() error {
+	errs := field.ErrorList{}
+	names := map[string]struct{}{}
+	for idx, s := range i.Services {
+		path := field.NewPath("services").Index(idx)
+		for _, msg := range validation.IsDNS1123Subdomain(s.Name) {
+			errs = append(errs, field.Invalid(path.Child("name"), s.Name, msg))
+		}
+		if _, found := names[s.Name]; found {
+			errs = append(errs, field.Duplicate(path.Child("name"), s.Name))
+		}
+		names[s.Name] = struct{}{}
+
+		if len(s.ServiceClassIdentity) == 0 {
+			errs = append(errs, field.Required(path.Child("serviceClassIdentity"), "service class identity is required"))
+		}
+		if len(s.ServiceEndpointDefinition) == 0 {
+			errs = append(errs, field.Required(path.Child("serviceEndpointDefinition"), "service endpoint definition is required"))
+		}
+
+		specErrs := s.ValidateServiceEndpointDefinition()
+		specErrs = append(specErrs, s.ValidateServiceClassIdentity()...)
+		specErrs = append(specErrs, s.ValidateHealthCheck()...)
+		specErrs = append(specErrs, s.ValidateConstraints()...)
+		// the specification's errors are reported relative to the service
+		for _, e := range specErrs {
+			e.Field = path.String() + strings.TrimPrefix(e.Field, "spec")
+			errs = append(errs, e)
+		}
+	}
+	return errs.ToAggregate()
+}
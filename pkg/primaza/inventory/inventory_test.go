@@ -0,0 +1,157 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inventory_test
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/primaza/constants"
+	"github.com/primaza/primaza/pkg/primaza/inventory"
+)
+
+const validInventory = `
+services:
+- name: orders-oracle
+  sla: L1
+  constraints:
+    environments: [prod]
+  serviceClassIdentity:
+  - name: type
+    value: oracle
+  serviceEndpointDefinition:
+  - name: host
+    value: oracle.example.com
+  - name: password
+    valueFromSecret:
+      name: orders-oracle
+      key: password
+- name: mailer
+  serviceClassIdentity:
+  - name: type
+    value: smtp
+  serviceEndpointDefinition:
+  - name: url
+    value: smtp://mail.example.com
+`
+
+func Test_Parse(t *testing.T) {
+	type test struct {
+		name    string
+		data    string
+		wantErr bool
+	}
+
+	tt := []test{
+		{name: "valid inventory", data: validInventory},
+		{name: "unknown field", data: "services:\n- name: mailer\n  url: smtp://mail.example.com\n", wantErr: true},
+		{
+			name:    "missing identity",
+			data:    "services:\n- name: mailer\n  serviceEndpointDefinition:\n  - name: url\n    value: smtp://mail.example.com\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid name",
+			data:    "services:\n- name: Mailer\n  serviceClassIdentity:\n  - name: type\n    value: smtp\n  serviceEndpointDefinition:\n  - name: url\n    value: smtp://mail.example.com\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid constraint",
+			data:    "services:\n- name: mailer\n  constraints:\n    environments: ['!']\n  serviceClassIdentity:\n  - name: type\n    value: smtp\n  serviceEndpointDefinition:\n  - name: url\n    value: smtp://mail.example.com\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tt {
+		_, err := inventory.Parse([]byte(tc.data))
+		switch {
+		case tc.wantErr && err == nil:
+			t.Errorf("%s: expected error, got nil", tc.name)
+		case !tc.wantErr && err != nil:
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+	}
+}
+
+func Test_Sync(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	stale := v1alpha1.RegisteredService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "legacy-ldap",
+			Namespace: "primaza-system",
+			Labels:    map[string]string{constants.PrimazaInventorySourceLabel: "saas"},
+		},
+	}
+	handWritten := v1alpha1.RegisteredService{
+		ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "primaza-system"},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&stale, &handWritten).Build()
+
+	inv, err := inventory.Parse([]byte(validInventory))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	importer := inventory.Importer{Client: cli, Namespace: "primaza-system", Source: "saas"}
+	// syncing twice must be idempotent
+	for i := 0; i < 2; i++ {
+		if err := importer.Sync(context.Background(), *inv); err != nil {
+			t.Fatalf("unexpected error syncing inventory: %v", err)
+		}
+	}
+
+	rsl := v1alpha1.RegisteredServiceList{}
+	if err := cli.List(context.Background(), &rsl, client.InNamespace("primaza-system")); err != nil {
+		t.Fatal(err)
+	}
+	found := map[string]v1alpha1.RegisteredService{}
+	for _, rs := range rsl.Items {
+		found[rs.Name] = rs
+	}
+
+	if _, ok := found["legacy-ldap"]; ok {
+		t.Errorf("expected service removed from inventory to be deleted")
+	}
+	if _, ok := found["postgres"]; !ok {
+		t.Errorf("expected service not imported from inventory to be left untouched")
+	}
+	oracle, ok := found["orders-oracle"]
+	switch {
+	case !ok:
+		t.Errorf("expected service orders-oracle to be imported")
+	case oracle.Labels[constants.PrimazaInventorySourceLabel] != "saas":
+		t.Errorf("expected imported service to be labeled with its source, got %v", oracle.Labels)
+	case oracle.Spec.SLA != "L1" || len(oracle.Spec.ServiceEndpointDefinition) != 2:
+		t.Errorf("unexpected imported service specification: %v", oracle.Spec)
+	}
+
+	// services not imported from the source are never overwritten
+	conflicting := inventory.Inventory{Services: []inventory.Service{{Name: "postgres"}}}
+	if err := importer.Sync(context.Background(), conflicting); err == nil {
+		t.Errorf("expected error overwriting a service not imported from inventory")
+	}
+}
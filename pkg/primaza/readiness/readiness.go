@@ -0,0 +1,89 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package readiness
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/celexpr"
+)
+
+// Check returns an error describing why the resource does not satisfy the
+// readiness gate.  A nil gate is always satisfied.
+func Check(gate *v1alpha1.ServiceClassReadinessGate, resource unstructured.Unstructured) error {
+	switch {
+	case gate == nil:
+		return nil
+	case gate.Expression != "":
+		return checkExpression(gate.Expression, resource)
+	case gate.ConditionType != "":
+		return checkCondition(gate.ConditionType, resource)
+	default:
+		return nil
+	}
+}
+
+func checkExpression(expression string, resource unstructured.Unstructured) error {
+	program, err := celexpr.Compile(expression)
+	if err != nil {
+		return fmt.Errorf("invalid readiness expression: %w", err)
+	}
+
+	ready, err := celexpr.EvaluateBool(program, resource.Object)
+	if err != nil {
+		return fmt.Errorf("readiness expression can not be evaluated: %w", err)
+	}
+	if !ready {
+		return fmt.Errorf("readiness expression '%s' is false", expression)
+	}
+	return nil
+}
+
+func checkCondition(conditionType string, resource unstructured.Unstructured) error {
+	conditions, _, err := unstructured.NestedSlice(resource.Object, "status", "conditions")
+	if err != nil {
+		return fmt.Errorf("status conditions can not be read: %w", err)
+	}
+
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != conditionType {
+			continue
+		}
+		if condition["status"] != "True" {
+			return fmt.Errorf("condition '%s' is %v", conditionType, condition["status"])
+		}
+		return nil
+	}
+	return fmt.Errorf("condition '%s' not found", conditionType)
+}
+
+// CheckBinding returns an error if the resource, implementing the
+// servicebinding.io ProvisionedService duck type, has not published the
+// name of its binding secret
+func CheckBinding(resource unstructured.Unstructured) error {
+	name, _, err := unstructured.NestedString(resource.Object, "status", "binding", "name")
+	if err != nil {
+		return fmt.Errorf("binding secret name can not be read: %w", err)
+	}
+	if name == "" {
+		return fmt.Errorf("binding secret not published")
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package readiness_test
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/primaza/readiness"
+)
+
+func Test_Check(t *testing.T) {
+	resource := unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"status": map[string]interface{}{
+				"phase": "Provisioning",
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Ready", "status": "True"},
+					map[string]interface{}{"type": "Upgraded", "status": "False"},
+				},
+			},
+		},
+	}
+
+	type test struct {
+		name    string
+		gate    *v1alpha1.ServiceClassReadinessGate
+		wantErr bool
+	}
+
+	tt := []test{
+		{name: "no gate"},
+		{name: "true condition", gate: &v1alpha1.ServiceClassReadinessGate{ConditionType: "Ready"}},
+		{name: "false condition", gate: &v1alpha1.ServiceClassReadinessGate{ConditionType: "Upgraded"}, wantErr: true},
+		{name: "missing condition", gate: &v1alpha1.ServiceClassReadinessGate{ConditionType: "Available"}, wantErr: true},
+		{name: "true expression", gate: &v1alpha1.ServiceClassReadinessGate{Expression: "self.status.phase != 'Failed'"}},
+		{name: "false expression", gate: &v1alpha1.ServiceClassReadinessGate{Expression: "self.status.phase == 'Running'"}, wantErr: true},
+		{name: "non boolean expression", gate: &v1alpha1.ServiceClassReadinessGate{Expression: "self.status.phase"}, wantErr: true},
+		{name: "failing expression", gate: &v1alpha1.ServiceClassReadinessGate{Expression: "self.status.ready"}, wantErr: true},
+	}
+
+	for _, tc := range tt {
+		err := readiness.Check(tc.gate, resource)
+		switch {
+		case tc.wantErr && err == nil:
+			t.Errorf("%s: expected resource not to be ready", tc.name)
+		case !tc.wantErr && err != nil:
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+	}
+}
+
+func Test_CheckBinding(t *testing.T) {
+	type test struct {
+		name     string
+		resource map[string]interface{}
+		wantErr  bool
+	}
+
+	tt := []test{
+		{
+			name: "published binding",
+			resource: map[string]interface{}{
+				"status": map[string]interface{}{
+					"binding": map[string]interface{}{"name": "db-binding"},
+				},
+			},
+		},
+		{
+			name:     "no status",
+			resource: map[string]interface{}{},
+			wantErr:  true,
+		},
+		{
+			name: "empty binding name",
+			resource: map[string]interface{}{
+				"status": map[string]interface{}{
+					"binding": map[string]interface{}{"name": ""},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tt {
+		err := readiness.CheckBinding(unstructured.Unstructured{Object: tc.resource})
+		switch {
+		case tc.wantErr && err == nil:
+			t.Errorf("%s: expected resource not to be ready", tc.name)
+		case !tc.wantErr && err != nil:
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+	}
+}
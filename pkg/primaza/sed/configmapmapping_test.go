@@ -0,0 +1,82 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sed_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/primaza/sed"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_ConfigMapRefMapping(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders-db-config", Namespace: "services"},
+		Data:       map[string]string{"host": "db.example.com"},
+		BinaryData: map[string][]byte{"port": []byte("5432")},
+	}
+	cli := fake.NewClientBuilder().WithObjects(cm).Build()
+
+	resource := unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{"configMap": "orders-db-config"},
+		},
+	}
+	name := ".spec.configMap"
+
+	type test struct {
+		key     string
+		want    string
+		wantErr bool
+	}
+
+	tt := []test{
+		{key: "host", want: "db.example.com"},
+		{key: "port", want: "5432"},
+		{key: "user", wantErr: true},
+	}
+
+	for _, tc := range tt {
+		key := tc.key
+		m, err := sed.NewSEDConfigMapRefMapping("services", resource, cli, v1alpha1.ServiceClassConfigMapRefFieldMapping{
+			Name:          tc.key,
+			ConfigMapName: v1alpha1.FieldMapping{JsonPathExpr: &name},
+			ConfigMapKey:  v1alpha1.FieldMapping{Constant: &key},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.InSecret() {
+			t.Errorf("expected config map mapping %s not to be stored in secret", tc.key)
+		}
+
+		v, err := m.ReadKey(context.Background())
+		switch {
+		case tc.wantErr && err == nil:
+			t.Errorf("expected error reading key %s, got %s", tc.key, *v)
+		case !tc.wantErr && err != nil:
+			t.Errorf("unexpected error reading key %s: %v", tc.key, err)
+		case !tc.wantErr && *v != tc.want:
+			t.Errorf("expected %s reading key %s, got %s", tc.want, tc.key, *v)
+		}
+	}
+}
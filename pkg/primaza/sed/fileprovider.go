@@ -0,0 +1,53 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Package sed contains logic for ServiceEndpointDefinition
+package sed
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileProviderName is the name the FileProvider is registered with
+const FileProviderName = "file"
+
+// FileProvider reads values from the files of a local directory: the key is
+// the path of the file, relative to the directory.  It is meant for testing
+// and for stores that are mounted as volumes, like CSI secret stores.
+type FileProvider struct {
+	dir string
+}
+
+var _ Provider = &FileProvider{}
+
+func NewFileProvider(dir string) *FileProvider {
+	return &FileProvider{dir: dir}
+}
+
+func (p *FileProvider) ReadValue(ctx context.Context, key string) (*string, error) {
+	if !filepath.IsLocal(key) {
+		return nil, fmt.Errorf("invalid key '%s': not a local path", key)
+	}
+
+	b, err := os.ReadFile(filepath.Join(p.dir, key))
+	if err != nil {
+		return nil, err
+	}
+	v := string(b)
+	return &v, nil
+}
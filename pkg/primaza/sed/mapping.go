@@ -17,10 +17,50 @@ limitations under the License.
 // Package sed contains logic for ServiceEndpointDefinition
 package sed
 
-import "context"
+import (
+	"context"
+	"errors"
+	"fmt"
+)
 
+// SEDMapping reads the value of a Service Endpoint Definition key.  A nil
+// value with no error means the key is omitted.
 type SEDMapping interface {
 	Key() string
 	ReadKey(context.Context) (*string, error)
 	InSecret() bool
 }
+
+// MappingError is returned when the value of a key can not be read
+type MappingError struct {
+	Key string
+	Err error
+}
+
+func (e *MappingError) Error() string {
+	return fmt.Sprintf("key '%s': %v", e.Key, e.Err)
+}
+
+func (e *MappingError) Unwrap() error {
+	return e.Err
+}
+
+// MissingKeys returns the keys of the MappingErrors contained in err
+func MissingKeys(err error) []string {
+	switch e := err.(type) { //nolint:errorlint
+	case nil:
+		return nil
+	case interface{ Unwrap() []error }:
+		keys := []string{}
+		for _, je := range e.Unwrap() {
+			keys = append(keys, MissingKeys(je)...)
+		}
+		return keys
+	}
+
+	var merr *MappingError
+	if errors.As(err, &merr) {
+		return []string{merr.Key}
+	}
+	return nil
+}
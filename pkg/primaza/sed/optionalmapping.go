@@ -0,0 +1,49 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Package sed contains logic for ServiceEndpointDefinition
+package sed
+
+import (
+	"context"
+)
+
+// SEDOptionalMapping wraps a mapping whose key can be omitted, or defaulted,
+// when its value can not be read
+type SEDOptionalMapping struct {
+	SEDMapping
+
+	defaultValue string
+}
+
+func NewSEDOptionalMapping(mapping SEDMapping, defaultValue string) *SEDOptionalMapping {
+	return &SEDOptionalMapping{
+		SEDMapping:   mapping,
+		defaultValue: defaultValue,
+	}
+}
+
+func (mapping *SEDOptionalMapping) ReadKey(ctx context.Context) (*string, error) {
+	value, err := mapping.SEDMapping.ReadKey(ctx)
+	switch {
+	case err == nil && value != nil:
+		return value, nil
+	case mapping.defaultValue != "":
+		v := mapping.defaultValue
+		return &v, nil
+	default:
+		return nil, nil
+	}
+}
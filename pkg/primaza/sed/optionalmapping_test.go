@@ -0,0 +1,81 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sed_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/primaza/sed"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_OptionalMappingReadKey(t *testing.T) {
+	resource := unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{"host": "db.example.com"},
+		},
+	}
+
+	type test struct {
+		jsonPath     string
+		defaultValue string
+		want         *string
+	}
+
+	host, port := "db.example.com", "5432"
+	tt := []test{
+		{jsonPath: ".spec.host", want: &host},
+		{jsonPath: ".spec.host", defaultValue: port, want: &host},
+		{jsonPath: ".status.port", defaultValue: port, want: &port},
+		{jsonPath: ".status.port"},
+	}
+
+	for _, tc := range tt {
+		m, err := sed.NewSEDResourceMapping(resource, v1alpha1.ServiceClassResourceFieldMapping{Name: "key", JsonPath: tc.jsonPath})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		v, err := sed.NewSEDOptionalMapping(m, tc.defaultValue).ReadKey(context.Background())
+		switch {
+		case err != nil:
+			t.Errorf("unexpected error reading %s: %v", tc.jsonPath, err)
+		case !reflect.DeepEqual(v, tc.want):
+			t.Errorf("expected %v reading %s, got %v", tc.want, tc.jsonPath, v)
+		}
+	}
+}
+
+func Test_MissingKeys(t *testing.T) {
+	err := errors.Join(
+		&sed.MappingError{Key: "host", Err: errors.New("not found")},
+		fmt.Errorf("wrapped: %w", &sed.MappingError{Key: "port", Err: errors.New("not found")}),
+		errors.New("unrelated"),
+	)
+
+	if keys := sed.MissingKeys(err); !reflect.DeepEqual(keys, []string{"host", "port"}) {
+		t.Errorf("expected missing keys [host port], got %v", keys)
+	}
+	if keys := sed.MissingKeys(nil); keys != nil {
+		t.Errorf("expected no missing keys, got %v", keys)
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Package sed contains logic for ServiceEndpointDefinition
+package sed
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/primaza/primaza/api/v1alpha1"
+)
+
+// Provider resolves ServiceEndpointDefinition values stored in an external
+// store, so that they do not need to be copied into Kubernetes secrets
+type Provider interface {
+	ReadValue(ctx context.Context, key string) (*string, error)
+}
+
+var (
+	providersLock sync.RWMutex
+	providers     = map[string]Provider{}
+)
+
+// RegisterProvider makes a provider available with the given name.  It returns
+// an error if another provider is already registered with the same name.
+func RegisterProvider(name string, provider Provider) error {
+	providersLock.Lock()
+	defer providersLock.Unlock()
+
+	if _, found := providers[name]; found {
+		return fmt.Errorf("provider '%s' already registered", name)
+	}
+	providers[name] = provider
+	return nil
+}
+
+// GetProvider returns the provider registered with the given name
+func GetProvider(name string) (Provider, error) {
+	providersLock.RLock()
+	defer providersLock.RUnlock()
+
+	if p, found := providers[name]; found {
+		return p, nil
+	}
+	return nil, fmt.Errorf("provider '%s' not registered", name)
+}
+
+// ReadProviderValue resolves the value referenced by ref
+func ReadProviderValue(ctx context.Context, ref v1alpha1.ServiceEndpointDefinitionProviderRef) (*string, error) {
+	p, err := GetProvider(ref.Name)
+	if err != nil {
+		return nil, err
+	}
+	return p.ReadValue(ctx, ref.Key)
+}
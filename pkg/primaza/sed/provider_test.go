@@ -0,0 +1,122 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sed_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/primaza/sed"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_RegisterProvider(t *testing.T) {
+	p := sed.NewFileProvider(t.TempDir())
+	if err := sed.RegisterProvider("test-register", p); err != nil {
+		t.Fatalf("unexpected error registering provider: %v", err)
+	}
+	if err := sed.RegisterProvider("test-register", p); err == nil {
+		t.Error("expected error registering provider twice")
+	}
+
+	if _, err := sed.GetProvider("test-register"); err != nil {
+		t.Errorf("unexpected error getting provider: %v", err)
+	}
+	if _, err := sed.GetProvider("test-missing"); err == nil {
+		t.Error("expected error getting unregistered provider")
+	}
+}
+
+func Test_FileProvider(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "db"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "db", "password"), []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p := sed.NewFileProvider(dir)
+
+	type test struct {
+		key     string
+		want    string
+		wantErr bool
+	}
+
+	tt := []test{
+		{key: "db/password", want: "secret"},
+		{key: "db/username", wantErr: true},
+		{key: "../password", wantErr: true},
+		{key: "/etc/passwd", wantErr: true},
+	}
+
+	for _, tc := range tt {
+		v, err := p.ReadValue(context.Background(), tc.key)
+		switch {
+		case tc.wantErr && err == nil:
+			t.Errorf("expected error reading key %s, got %s", tc.key, *v)
+		case !tc.wantErr && err != nil:
+			t.Errorf("unexpected error reading key %s: %v", tc.key, err)
+		case !tc.wantErr && *v != tc.want:
+			t.Errorf("expected %s reading key %s, got %s", tc.want, tc.key, *v)
+		}
+	}
+}
+
+func Test_ProviderRefMapping(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "orders-db"), []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := sed.RegisterProvider("test-mapping", sed.NewFileProvider(dir)); err != nil {
+		t.Fatal(err)
+	}
+
+	resource := unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "orders-db"},
+		},
+	}
+	path := ".metadata.name"
+	m, err := sed.NewSEDProviderRefMapping(resource, v1alpha1.ServiceClassProviderRefFieldMapping{
+		Name:     "password",
+		Provider: "test-mapping",
+		Key:      v1alpha1.FieldMapping{JsonPathExpr: &path},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ref, err := m.ProviderRef()
+	if err != nil {
+		t.Fatalf("unexpected error reading provider reference: %v", err)
+	}
+	if ref.Name != "test-mapping" || ref.Key != "orders-db" {
+		t.Errorf("unexpected provider reference: %v", ref)
+	}
+
+	v, err := m.ReadKey(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error reading key: %v", err)
+	}
+	if *v != "secret" {
+		t.Errorf("expected secret, got %s", *v)
+	}
+}
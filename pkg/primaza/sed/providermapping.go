@@ -0,0 +1,75 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Package sed contains logic for ServiceEndpointDefinition
+package sed
+
+import (
+	"context"
+
+	"github.com/primaza/primaza/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// SEDProviderRefMapping maps a key to a value stored in an external store.
+// RegisteredServices reference such values instead of copying them.
+type SEDProviderRefMapping struct {
+	resource unstructured.Unstructured
+
+	key         string
+	provider    string
+	providerKey v1alpha1.FieldMapping
+}
+
+func NewSEDProviderRefMapping(
+	resource unstructured.Unstructured,
+	mapping v1alpha1.ServiceClassProviderRefFieldMapping,
+) (*SEDProviderRefMapping, error) {
+	return &SEDProviderRefMapping{
+		resource:    resource,
+		key:         mapping.Name,
+		provider:    mapping.Provider,
+		providerKey: mapping.Key,
+	}, nil
+}
+
+func (s *SEDProviderRefMapping) Key() string {
+	return s.key
+}
+
+// ProviderRef returns the reference to the value in the provider's store
+func (mapping *SEDProviderRefMapping) ProviderRef() (*v1alpha1.ServiceEndpointDefinitionProviderRef, error) {
+	key, err := readValue(mapping.providerKey, mapping.resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1alpha1.ServiceEndpointDefinitionProviderRef{
+		Name: mapping.provider,
+		Key:  *key,
+	}, nil
+}
+
+func (mapping *SEDProviderRefMapping) ReadKey(ctx context.Context) (*string, error) {
+	ref, err := mapping.ProviderRef()
+	if err != nil {
+		return nil, err
+	}
+	return ReadProviderValue(ctx, *ref)
+}
+
+func (s *SEDProviderRefMapping) InSecret() bool {
+	return true
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sed
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ProvisionedServiceKey is the key reported when the binding secret of a
+// ProvisionedService can not be read
+const ProvisionedServiceKey = "status.binding.name"
+
+// SEDProvisionedServiceMapping reads all the keys of the binding secret
+// published by a resource implementing the servicebinding.io
+// ProvisionedService duck type
+type SEDProvisionedServiceMapping struct {
+	namespace string
+	resource  unstructured.Unstructured
+	cli       client.Reader
+}
+
+// NewSEDProvisionedServiceMapping returns the mapping for the given resource.
+// The binding secret is looked up in the resource's namespace, or in the
+// given one for cluster-scoped resources.
+func NewSEDProvisionedServiceMapping(
+	namespace string,
+	resource unstructured.Unstructured,
+	cli client.Reader,
+) *SEDProvisionedServiceMapping {
+	return &SEDProvisionedServiceMapping{
+		namespace: namespace,
+		resource:  resource,
+		cli:       cli,
+	}
+}
+
+func (mapping *SEDProvisionedServiceMapping) Key() string {
+	return ProvisionedServiceKey
+}
+
+// ReadKey returns the name of the binding secret
+func (mapping *SEDProvisionedServiceMapping) ReadKey(ctx context.Context) (*string, error) {
+	name, _, err := unstructured.NestedString(mapping.resource.Object, "status", "binding", "name")
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, fmt.Errorf("binding secret not published")
+	}
+	return &name, nil
+}
+
+// ReadKeys returns all the keys of the binding secret
+func (mapping *SEDProvisionedServiceMapping) ReadKeys(ctx context.Context) (map[string]string, error) {
+	name, err := mapping.ReadKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := mapping.resource.GetNamespace()
+	if namespace == "" {
+		namespace = mapping.namespace
+	}
+
+	s := &corev1.Secret{}
+	if err := mapping.cli.Get(ctx, types.NamespacedName{Namespace: namespace, Name: *name}, s); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(s.Data))
+	for k, v := range s.Data {
+		values[k] = string(v)
+	}
+	return values, nil
+}
+
+func (mapping *SEDProvisionedServiceMapping) InSecret() bool {
+	return true
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sed_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/primaza/primaza/pkg/primaza/sed"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_ProvisionedServiceMapping(t *testing.T) {
+	cli := fake.NewClientBuilder().WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "orders-db-binding", Namespace: "services"},
+			Data: map[string][]byte{
+				"type":     []byte("postgresql"),
+				"host":     []byte("orders-db.services.svc"),
+				"password": []byte("secret"),
+			},
+		},
+	).Build()
+
+	published := unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "orders-db", "namespace": "services"},
+			"status": map[string]interface{}{
+				"binding": map[string]interface{}{"name": "orders-db-binding"},
+			},
+		},
+	}
+	m := sed.NewSEDProvisionedServiceMapping("services", published, cli)
+	values, err := m.ReadKeys(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error reading keys: %v", err)
+	}
+	expected := map[string]string{
+		"type":     "postgresql",
+		"host":     "orders-db.services.svc",
+		"password": "secret",
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v reading keys, got %v", expected, values)
+	}
+
+	unpublished := unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "orders-db", "namespace": "services"},
+		},
+	}
+	m = sed.NewSEDProvisionedServiceMapping("services", unpublished, cli)
+	if _, err := m.ReadKeys(context.Background()); err == nil {
+		t.Errorf("expected error reading keys of unpublished binding secret")
+	}
+}
@@ -21,7 +21,9 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/google/cel-go/cel"
 	"github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/celexpr"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/util/jsonpath"
 )
@@ -30,24 +32,35 @@ import (
 type SEDResourceMapping struct {
 	resource unstructured.Unstructured
 
-	key    string
-	path   *jsonpath.JSONPath
-	secret bool
+	key        string
+	path       *jsonpath.JSONPath
+	expression cel.Program
+	secret     bool
 }
 
 func NewSEDResourceMapping(resource unstructured.Unstructured, mapping v1alpha1.ServiceClassResourceFieldMapping) (*SEDResourceMapping, error) {
+	m := &SEDResourceMapping{
+		resource: resource,
+		key:      mapping.Name,
+		secret:   mapping.Secret,
+	}
+
+	if mapping.Expression != "" {
+		prg, err := celexpr.Compile(mapping.Expression)
+		if err != nil {
+			return nil, err
+		}
+		m.expression = prg
+		return m, nil
+	}
+
 	path := jsonpath.New("")
 	err := path.Parse(fmt.Sprintf("{%s}", mapping.JsonPath))
 	if err != nil {
 		return nil, err
 	}
-
-	return &SEDResourceMapping{
-		resource: resource,
-		key:      mapping.Name,
-		path:     path,
-		secret:   mapping.Secret,
-	}, nil
+	m.path = path
+	return m, nil
 }
 
 func (s *SEDResourceMapping) Key() string {
@@ -55,17 +68,10 @@ func (s *SEDResourceMapping) Key() string {
 }
 
 func (mapping *SEDResourceMapping) ReadKey(ctx context.Context) (*string, error) {
-	results, err := mapping.path.FindResults(mapping.resource.Object)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(results) != 1 || len(results[0]) != 1 {
-		return nil, fmt.Errorf("jsonPath lookup into resource returned multiple results: %v", results)
+	if mapping.expression != nil {
+		return celexpr.Evaluate(mapping.expression, mapping.resource.Object)
 	}
-
-	value := fmt.Sprintf("%v", results[0][0])
-	return &value, nil
+	return readSingleJsonPath(mapping.path, mapping.resource)
 }
 
 func (s *SEDResourceMapping) InSecret() bool {
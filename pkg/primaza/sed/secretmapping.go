@@ -22,6 +22,7 @@ import (
 	"fmt"
 
 	"github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/celexpr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
@@ -32,26 +33,28 @@ import (
 type SEDSecretRefMapping struct {
 	namespace string
 	resource  unstructured.Unstructured
-	cli       client.Client
+	cli       client.Reader
 
-	key        string
-	secretName v1alpha1.FieldMapping
-	secretKey  v1alpha1.FieldMapping
+	key             string
+	secretName      v1alpha1.FieldMapping
+	secretKey       v1alpha1.FieldMapping
+	secretNamespace *v1alpha1.FieldMapping
 }
 
 func NewSEDSecretRefMapping(
 	namespace string,
 	resource unstructured.Unstructured,
-	cli client.Client,
+	cli client.Reader,
 	mapping v1alpha1.ServiceClassSecretRefFieldMapping,
 ) (*SEDSecretRefMapping, error) {
 	return &SEDSecretRefMapping{
-		namespace:  namespace,
-		resource:   resource,
-		cli:        cli,
-		key:        mapping.Name,
-		secretKey:  mapping.SecretKey,
-		secretName: mapping.SecretName,
+		namespace:       namespace,
+		resource:        resource,
+		cli:             cli,
+		key:             mapping.Name,
+		secretKey:       mapping.SecretKey,
+		secretName:      mapping.SecretName,
+		secretNamespace: mapping.SecretNamespace,
 	}, nil
 }
 
@@ -69,8 +72,10 @@ func readValue(mapping v1alpha1.FieldMapping, resource unstructured.Unstructured
 			return nil, err
 		}
 		return readSingleJsonPath(jsonPath, resource)
+	case mapping.Expression != nil:
+		return celexpr.EvaluateExpression(*mapping.Expression, resource.Object)
 	default:
-		return nil, fmt.Errorf("invalid mapping: neither Constant nor JsonPathExpr defined")
+		return nil, fmt.Errorf("invalid mapping: neither Constant, JsonPathExpr, nor Expression defined")
 	}
 }
 
@@ -83,10 +88,18 @@ func (mapping *SEDSecretRefMapping) ReadKey(ctx context.Context) (*string, error
 	if err != nil {
 		return nil, err
 	}
+	secNamespace := mapping.namespace
+	if mapping.secretNamespace != nil {
+		ns, err := readValue(*mapping.secretNamespace, mapping.resource)
+		if err != nil {
+			return nil, err
+		}
+		secNamespace = *ns
+	}
 
 	s := &corev1.Secret{}
 	ok := types.NamespacedName{
-		Namespace: mapping.namespace,
+		Namespace: secNamespace,
 		Name:      *secName,
 	}
 	if err := mapping.cli.Get(ctx, ok, s, &client.GetOptions{}); err != nil {
@@ -98,7 +111,7 @@ func (mapping *SEDSecretRefMapping) ReadKey(ctx context.Context) (*string, error
 		return &v, nil
 	}
 
-	return nil, fmt.Errorf("secret key '%s/%s:%s' not Found", mapping.namespace, *secName, *secKey)
+	return nil, fmt.Errorf("secret key '%s/%s:%s' not Found", secNamespace, *secName, *secKey)
 }
 
 func readSingleJsonPath(path *jsonpath.JSONPath, resource unstructured.Unstructured) (*string, error) {
@@ -0,0 +1,87 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sed_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/primaza/primaza/api/v1alpha1"
+	"github.com/primaza/primaza/pkg/primaza/sed"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func Test_SecretRefMappingNamespace(t *testing.T) {
+	cli := fake.NewClientBuilder().WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "orders-db", Namespace: "services"},
+			Data:       map[string][]byte{"password": []byte("default")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "orders-db", Namespace: "crossplane-system"},
+			Data:       map[string][]byte{"password": []byte("explicit")},
+		},
+	).Build()
+
+	// cluster-scoped resources have no namespace
+	resource := unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"writeConnectionSecretToRef": map[string]interface{}{
+					"name":      "orders-db",
+					"namespace": "crossplane-system",
+				},
+			},
+		},
+	}
+	name := ".spec.writeConnectionSecretToRef.name"
+	namespace := ".spec.writeConnectionSecretToRef.namespace"
+	key := "password"
+
+	type test struct {
+		secretNamespace *v1alpha1.FieldMapping
+		want            string
+	}
+
+	tt := []test{
+		{want: "default"},
+		{secretNamespace: &v1alpha1.FieldMapping{JsonPathExpr: &namespace}, want: "explicit"},
+	}
+
+	for _, tc := range tt {
+		m, err := sed.NewSEDSecretRefMapping("services", resource, cli, v1alpha1.ServiceClassSecretRefFieldMapping{
+			Name:            "password",
+			SecretName:      v1alpha1.FieldMapping{JsonPathExpr: &name},
+			SecretKey:       v1alpha1.FieldMapping{Constant: &key},
+			SecretNamespace: tc.secretNamespace,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		v, err := m.ReadKey(context.Background())
+		switch {
+		case err != nil:
+			t.Errorf("unexpected error reading key: %v", err)
+		case *v != tc.want:
+			t.Errorf("expected %s reading key, got %s", tc.want, *v)
+		}
+	}
+}
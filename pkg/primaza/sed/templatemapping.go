@@ -0,0 +1,122 @@
+/*
+Copyright 2023 The Primaza Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Package sed contains logic for ServiceEndpointDefinition
+package sed
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"text/template"
+
+	"github.com/primaza/primaza/api/v1alpha1"
+)
+
+// TemplateError is returned when a template mapping can not be parsed or
+// rendered
+type TemplateError struct {
+	Key string
+	Err error
+}
+
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("template for key '%s': %v", e.Key, e.Err)
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// templateFuncs is the set of functions available to template mappings
+var templateFuncs = template.FuncMap{
+	"urlencode": url.QueryEscape,
+	"base64": func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	},
+	"default": func(d string, s string) string {
+		if s == "" {
+			return d
+		}
+		return s
+	},
+	"join": func(sep string, elems ...string) string {
+		return strings.Join(elems, sep)
+	},
+}
+
+// SEDTemplateMapping composes a key from the values of other keys.  The
+// values are read from the source mappings, that can not be template mappings.
+type SEDTemplateMapping struct {
+	key      string
+	template *template.Template
+	secret   bool
+	sources  []SEDMapping
+}
+
+func NewSEDTemplateMapping(mapping v1alpha1.ServiceClassTemplateFieldMapping, sources []SEDMapping) (*SEDTemplateMapping, error) {
+	// absent keys render as empty strings, so that optional keys can be
+	// given a fallback with the default function
+	t, err := template.New(mapping.Name).
+		Option("missingkey=zero").
+		Funcs(templateFuncs).
+		Parse(mapping.Template)
+	if err != nil {
+		return nil, &TemplateError{Key: mapping.Name, Err: err}
+	}
+
+	return &SEDTemplateMapping{
+		key:      mapping.Name,
+		template: t,
+		secret:   mapping.Secret,
+		sources:  sources,
+	}, nil
+}
+
+func (s *SEDTemplateMapping) Key() string {
+	return s.key
+}
+
+// Render executes the template with the given values of the other keys
+func (mapping *SEDTemplateMapping) Render(values map[string]string) (*string, error) {
+	var b strings.Builder
+	if err := mapping.template.Execute(&b, values); err != nil {
+		return nil, &TemplateError{Key: mapping.key, Err: err}
+	}
+
+	value := b.String()
+	return &value, nil
+}
+
+func (mapping *SEDTemplateMapping) ReadKey(ctx context.Context) (*string, error) {
+	values := map[string]string{}
+	for _, m := range mapping.sources {
+		v, err := m.ReadKey(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil {
+			continue
+		}
+		values[m.Key()] = *v
+	}
+	return mapping.Render(values)
+}
+
+func (s *SEDTemplateMapping) InSecret() bool {
+	return s.secret
+}